@@ -0,0 +1,18 @@
+package freecache
+
+import "github.com/coocood/freecache"
+
+// ErrNotFound is returned by Cache.Get when key is absent or expired
+var ErrNotFound = freecache.ErrNotFound
+
+type Cache interface {
+	Get(key []byte) ([]byte, error)
+	Set(key, val []byte, expireIn int) error
+	Delete(key []byte) (affected bool)
+	EntryCount() int64
+}
+
+var (
+	_ Cache = (*freecacherepo)(nil)
+	_ Cache = (*rediscacherepo)(nil)
+)
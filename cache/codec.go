@@ -0,0 +1,69 @@
+package freecache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec converts typed cache values to bytes stored in freecache and back
+type Codec[V any] interface {
+	Marshal(V) ([]byte, error)
+	Unmarshal([]byte) (V, error)
+}
+
+type jsonCodec[V any] struct{}
+
+func JSONCodec[V any]() Codec[V] {
+	return jsonCodec[V]{}
+}
+func (jsonCodec[V]) Marshal(v V) ([]byte, error) {
+	return json.Marshal(v)
+}
+func (jsonCodec[V]) Unmarshal(data []byte) (V, error) {
+	var v V
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
+
+type gobCodec[V any] struct{}
+
+func GobCodec[V any]() Codec[V] {
+	return gobCodec[V]{}
+}
+func (gobCodec[V]) Marshal(v V) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+func (gobCodec[V]) Unmarshal(data []byte) (V, error) {
+	var v V
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&v)
+	return v, err
+}
+
+type protoCodec[V proto.Message] struct{}
+
+// ProtoCodec works with generated message pointers, e.g. ProtoCodec[*books_pb.Book]()
+func ProtoCodec[V proto.Message]() Codec[V] {
+	return protoCodec[V]{}
+}
+func (protoCodec[V]) Marshal(v V) ([]byte, error) {
+	return proto.Marshal(v)
+}
+func (protoCodec[V]) Unmarshal(data []byte) (V, error) {
+	var zero V
+	msg, ok := zero.ProtoReflect().Type().New().Interface().(V)
+	if !ok {
+		return zero, fmt.Errorf("unable to create message of type %T", zero)
+	}
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return zero, err
+	}
+	return msg, nil
+}
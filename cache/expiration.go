@@ -0,0 +1,29 @@
+package freecache
+
+// GetWithTTL returns value with number of seconds left before it expires. Zero ttl means value never expires
+func (r *freecacherepo) GetWithTTL(key []byte) (value []byte, ttl int, err error) {
+	value, header, err := r.get(key)
+	if err != nil {
+		return nil, 0, err
+	}
+	if header.slide > 0 {
+		return value, header.slide, nil
+	}
+	// ttl isn't known to get, so it's read separately and may belong to value stored meanwhile
+	left, err := r.cache.TTL(key)
+	if err != nil {
+		return nil, 0, err
+	}
+	return value, int(left), nil
+}
+
+// Touch updates key's ttl without changing its value. Sliding value keeps sliding by its own ttl on next reads
+func (r *freecacherepo) Touch(key []byte, expireIn int) error {
+	return r.cache.Touch(key, expireIn)
+}
+
+// SetSliding stores value which ttl is reset to expireIn on every read, like a session.
+// Plain Set of the key turns sliding expiration off
+func (r *freecacherepo) SetSliding(key, val []byte, expireIn int) error {
+	return r.set(key, val, expireIn, valueHeader{slide: expireIn})
+}
@@ -0,0 +1,111 @@
+package freecache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetWithTTL(t *testing.T) {
+	cache := NewFreeCache(0)
+	assert.NoError(t, cache.Set([]byte("forever"), []byte("1"), 0))
+	assert.NoError(t, cache.Set([]byte("minute"), []byte("2"), 60))
+
+	value, ttl, err := cache.GetWithTTL([]byte("forever"))
+	assert.NoError(t, err)
+	assert.Equal(t, "1", string(value))
+	assert.Zero(t, ttl)
+
+	value, ttl, err = cache.GetWithTTL([]byte("minute"))
+	assert.NoError(t, err)
+	assert.Equal(t, "2", string(value))
+	assert.InDelta(t, 60, ttl, 1)
+
+	_, _, err = cache.GetWithTTL([]byte("missing"))
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+func TestTouch(t *testing.T) {
+	cache := NewFreeCache(0)
+	assert.NoError(t, cache.Set([]byte("key"), []byte("1"), 10))
+
+	assert.NoError(t, cache.Touch([]byte("key"), 300))
+	_, ttl, err := cache.GetWithTTL([]byte("key"))
+	assert.NoError(t, err)
+	assert.InDelta(t, 300, ttl, 1)
+
+	assert.ErrorIs(t, cache.Touch([]byte("missing"), 10), ErrNotFound)
+}
+func TestSlidingExpiration(t *testing.T) {
+	cache := NewFreeCache(0)
+	assert.NoError(t, cache.SetSliding([]byte("session"), []byte("user"), 30))
+	assert.NoError(t, cache.Set([]byte("plain"), []byte("1"), 30))
+
+	// emulating passed time
+	assert.NoError(t, cache.cache.Touch([]byte("session"), 5))
+	assert.NoError(t, cache.cache.Touch([]byte("plain"), 5))
+
+	got, err := cache.Get([]byte("session"))
+	assert.NoError(t, err)
+	assert.Equal(t, "user", string(got))
+	_, err = cache.Get([]byte("plain"))
+	assert.NoError(t, err)
+
+	ttl, err := cache.cache.TTL([]byte("session"))
+	assert.NoError(t, err)
+	assert.InDelta(t, 30, ttl, 1)
+	ttl, err = cache.cache.TTL([]byte("plain"))
+	assert.NoError(t, err)
+	assert.InDelta(t, 5, ttl, 1)
+
+	assert.NoError(t, cache.cache.Touch([]byte("session"), 5))
+	_, left, err := cache.GetWithTTL([]byte("session"))
+	assert.NoError(t, err)
+	assert.Equal(t, 30, left)
+
+	assert.NoError(t, cache.Set([]byte("session"), []byte("user"), 5))
+	_, left, err = cache.GetWithTTL([]byte("session"))
+	assert.NoError(t, err)
+	assert.InDelta(t, 5, left, 1)
+
+	assert.NoError(t, cache.SetSliding([]byte("session"), []byte("user"), 30))
+	assert.True(t, cache.Delete([]byte("session")))
+	_, err = cache.Get([]byte("session"))
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+func TestSlidingExpirationStats(t *testing.T) {
+	cache := NewFreeCache(0)
+	assert.NoError(t, cache.SetSliding([]byte("session"), []byte("user"), 30))
+	assert.NoError(t, cache.Set([]byte("plain"), []byte("1"), 0))
+
+	for _, key := range []string{"session", "plain", "missing"} {
+		_, _ = cache.Get([]byte(key))
+	}
+	stats := cache.Stats()
+	assert.EqualValues(t, 2, stats.EntryCount)
+	assert.EqualValues(t, 3, stats.LookupCount)
+	assert.EqualValues(t, 2, stats.HitCount)
+	assert.EqualValues(t, 1, stats.MissCount)
+}
+func TestSlidingExpirationConcurrentSet(t *testing.T) {
+	cache := NewFreeCache(0)
+
+	// plain Set must never get ttl of the sliding value it replaced
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, cache.SetSliding([]byte("session"), []byte("user"), 30))
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for j := 0; j < 10; j++ {
+				_, _ = cache.Get([]byte("session"))
+			}
+		}()
+		assert.NoError(t, cache.Set([]byte("session"), []byte("user"), 5))
+		<-done
+
+		ttl, err := cache.cache.TTL([]byte("session"))
+		assert.NoError(t, err)
+		if !assert.LessOrEqual(t, ttl, uint32(5)) {
+			return
+		}
+	}
+}
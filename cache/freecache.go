@@ -1,46 +1,171 @@
 package freecache
 
 import (
+	"encoding/binary"
 	"sync"
 
 	"github.com/coocood/freecache"
+	"golang.org/x/sync/singleflight"
 )
 
+// metaSizeRatio is the ratio of cache size to its bookkeeping storage size
+const metaSizeRatio = 16
+
+// freecacherepo's Get, Set and Delete don't take any locks of their own: freecache splits
+// the storage into 256 segments, each guarded by its own mutex.
+// Negative results live in meta, so they don't compete with callers' entries for space and aren't visible
+// in their statistics. meta evicts entries silently when full, so only what is safe to lose is kept there.
+// Every value in cache starts with header carrying its sliding ttl and tags, see encodeValue
 type freecacherepo struct {
-	sync.Mutex
-	cache *freecache.Cache
+	cache       *freecache.Cache
+	meta        *freecache.Cache
+	size        int // size passed to NewFreeCache, limits snapshot entries
+	loads       singleflight.Group
+	negativeTTL int
 }
 
-func NewFreeCache(size int) *freecacherepo {
-	return &freecacherepo{cache: freecache.NewCache(size)}
+type Option func(*freecacherepo)
+
+// WithNegativeCaching makes GetOrLoad remember keys the loader couldn't find for ttl seconds
+func WithNegativeCaching(ttl int) Option {
+	return func(r *freecacherepo) {
+		r.negativeTTL = ttl
+	}
 }
-func (c *freecacherepo) EntryCount() int64 {
-	c.Lock()
-	defer c.Unlock()
 
+// NewFreeCache creates cache of size bytes. Additional size/16 bytes, but at least 512 kb, are allocated for bookkeeping
+func NewFreeCache(size int, options ...Option) *freecacherepo {
+	repo := &freecacherepo{cache: freecache.NewCache(size), meta: freecache.NewCache(size / metaSizeRatio), size: size}
+	for _, option := range options {
+		option(repo)
+	}
+	return repo
+}
+func (c *freecacherepo) EntryCount() int64 {
 	return c.cache.EntryCount()
 }
 func (r *freecacherepo) Get(uuid []byte) ([]byte, error) {
-	r.Lock()
-	defer r.Unlock()
-	got, err := r.cache.Get(uuid)
-	return got, err
+	value, _, err := r.get(uuid)
+	return value, err
 }
-
 func (r *freecacherepo) Set(key, val []byte, expireIn int) error {
-	r.Lock()
-	defer r.Unlock()
+	return r.set(key, val, expireIn, valueHeader{})
+}
+func (r *freecacherepo) Delete(key []byte) (affected bool) {
+	return r.cache.Del(key)
+}
 
-	err := r.cache.Set(key, val, expireIn)
+// get reads value and extends sliding one under the same segment lock, so the ttl can't be applied
+// to a value stored by concurrent Set after the read
+func (r *freecacherepo) get(key []byte) (value []byte, header valueHeader, err error) {
+	var found bool
+	_, _, err = r.cache.Update(key, func(stored []byte, ok bool) ([]byte, bool, int) {
+		if found = ok; !ok {
+			return nil, false, 0
+		}
+		value, header = decodeValue(stored)
+		return stored, header.slide > 0, header.slide
+	})
 	if err != nil {
-		return err
+		return nil, valueHeader{}, err
 	}
-	return nil
+	if !found {
+		return nil, valueHeader{}, ErrNotFound
+	}
+	return value, header, nil
 }
 
-func (r *freecacherepo) Delete(key []byte) (affected bool) {
-	r.Lock()
-	defer r.Unlock()
+// set stores value with header. Buffers are pooled, since freecache copies value into its own storage
+func (r *freecacherepo) set(key, val []byte, expireIn int, header valueHeader) error {
+	buffer := valueBuffers.Get().(*[]byte)
+	*buffer = encodeValue((*buffer)[:0], val, header)
+	err := r.cache.Set(key, *buffer, expireIn)
+	valueBuffers.Put(buffer)
+	return err
+}
 
-	return r.cache.Del(key)
+var valueBuffers = sync.Pool{New: func() any { return new([]byte) }}
+
+const (
+	headerSliding byte = 1 << iota
+	headerTagged
+)
+
+// valueHeader keeps what Get and InvalidateTag need to know about value without any other lookup
+type valueHeader struct {
+	slide int    // sliding ttl, zero if value doesn't slide
+	tags  []byte // tags encoded by encodeTags, nil if value isn't tagged
+}
+
+// encodeValue appends value with header to buffer. Header starts with flags byte, which is zero for plain values.
+// Sliding ttl follows as uvarint, then length-prefixed tags
+func encodeValue(buffer, value []byte, header valueHeader) []byte {
+	var flags byte
+	if header.slide > 0 {
+		flags |= headerSliding
+	}
+	if len(header.tags) > 0 {
+		flags |= headerTagged
+	}
+	buffer = append(buffer, flags)
+	if flags&headerSliding != 0 {
+		buffer = binary.AppendUvarint(buffer, uint64(header.slide))
+	}
+	if flags&headerTagged != 0 {
+		buffer = binary.AppendUvarint(buffer, uint64(len(header.tags)))
+		buffer = append(buffer, header.tags...)
+	}
+	return append(buffer, value...)
+}
+
+// decodeValue strips header. Malformed header is treated as absent one
+func decodeValue(data []byte) (value []byte, header valueHeader) {
+	if len(data) == 0 {
+		return data, header
+	}
+	flags, rest := data[0], data[1:]
+	if flags&headerSliding != 0 {
+		ttl, n := binary.Uvarint(rest)
+		if n <= 0 {
+			return rest, valueHeader{}
+		}
+		header.slide, rest = int(ttl), rest[n:]
+	}
+	if flags&headerTagged != 0 {
+		size, n := binary.Uvarint(rest)
+		if n <= 0 || size > uint64(len(rest)-n) {
+			return rest, valueHeader{}
+		}
+		header.tags, rest = rest[n:n+int(size)], rest[n+int(size):]
+	}
+	return rest, header
+}
+
+// encodeTags writes every tag prefixed by its length
+func encodeTags(tags []string) []byte {
+	var encoded []byte
+	for _, tag := range tags {
+		encoded = binary.AppendUvarint(encoded, uint64(len(tag)))
+		encoded = append(encoded, tag...)
+	}
+	return encoded
+}
+func (h valueHeader) hasTag(tag string) bool {
+	for tags := h.tags; len(tags) > 0; {
+		size, n := binary.Uvarint(tags)
+		if n <= 0 || size > uint64(len(tags)-n) {
+			return false
+		}
+		if string(tags[n:n+int(size)]) == tag {
+			return true
+		}
+		tags = tags[n+int(size):]
+	}
+	return false
+}
+func internalKey(kind string, key []byte) []byte {
+	result := make([]byte, 0, len(kind)+1+len(key))
+	result = append(result, kind...)
+	result = append(result, ':')
+	return append(result, key...)
 }
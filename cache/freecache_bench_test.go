@@ -0,0 +1,120 @@
+package freecache
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/coocood/freecache"
+)
+
+// mutexcacherepo is the previous implementation, which took a global mutex on every call.
+// It's kept here only to compare against freecacherepo
+type mutexcacherepo struct {
+	sync.Mutex
+	cache *freecache.Cache
+}
+
+func (r *mutexcacherepo) Get(key []byte) ([]byte, error) {
+	r.Lock()
+	defer r.Unlock()
+	return r.cache.Get(key)
+}
+func (r *mutexcacherepo) Set(key, val []byte, expireIn int) error {
+	r.Lock()
+	defer r.Unlock()
+	return r.cache.Set(key, val, expireIn)
+}
+func (r *mutexcacherepo) Delete(key []byte) bool {
+	r.Lock()
+	defer r.Unlock()
+	return r.cache.Del(key)
+}
+
+type benchCache interface {
+	Get([]byte) ([]byte, error)
+	Set([]byte, []byte, int) error
+	Delete([]byte) bool
+}
+
+const benchKeys = 1024
+
+func benchImplementations() map[string]func() benchCache {
+	return map[string]func() benchCache{
+		"Mutex":    func() benchCache { return &mutexcacherepo{cache: freecache.NewCache(16 * 1024 * 1024)} },
+		"LockFree": func() benchCache { return NewFreeCache(16 * 1024 * 1024) },
+	}
+}
+func benchKeySet() [][]byte {
+	keys := make([][]byte, benchKeys)
+	for i := range keys {
+		keys[i] = []byte("book:" + strconv.Itoa(i))
+	}
+	return keys
+}
+func BenchmarkParallelGet(b *testing.B) {
+	keys := benchKeySet()
+	for name, create := range benchImplementations() {
+		b.Run(name, func(b *testing.B) {
+			cache := create()
+			for _, k := range keys {
+				_ = cache.Set(k, []byte("value"), 0)
+			}
+			var counter atomic.Uint64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := counter.Add(1) * 7919
+				for pb.Next() {
+					_, _ = cache.Get(keys[i%benchKeys])
+					i++
+				}
+			})
+		})
+	}
+}
+func BenchmarkParallelSet(b *testing.B) {
+	keys := benchKeySet()
+	for name, create := range benchImplementations() {
+		b.Run(name, func(b *testing.B) {
+			cache := create()
+			var counter atomic.Uint64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := counter.Add(1) * 7919
+				for pb.Next() {
+					_ = cache.Set(keys[i%benchKeys], []byte("value"), 0)
+					i++
+				}
+			})
+		})
+	}
+}
+func BenchmarkParallelMixed(b *testing.B) {
+	keys := benchKeySet()
+	for name, create := range benchImplementations() {
+		b.Run(name, func(b *testing.B) {
+			cache := create()
+			for _, k := range keys {
+				_ = cache.Set(k, []byte("value"), 0)
+			}
+			var counter atomic.Uint64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := counter.Add(1) * 7919
+				for pb.Next() {
+					key := keys[i%benchKeys]
+					switch i % 10 {
+					case 0:
+						_ = cache.Set(key, []byte("value"), 0)
+					case 1:
+						cache.Delete(key)
+					default:
+						_, _ = cache.Get(key)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
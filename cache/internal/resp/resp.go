@@ -0,0 +1,140 @@
+// Package resp implements the subset of redis serialization protocol used by the cache package
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Error is an error reply sent by redis server
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+var ErrProtocol = errors.New("resp: malformed reply")
+
+// WriteCommand writes command as an array of bulk strings and flushes writer
+func WriteCommand(w *bufio.Writer, args ...[]byte) error {
+	if _, err := fmt.Fprintf(w, "*%d\r\n", len(args)); err != nil {
+		return err
+	}
+	for _, arg := range args {
+		if err := WriteBulk(w, arg); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+func WriteBulk(w *bufio.Writer, data []byte) error {
+	if data == nil {
+		_, err := w.WriteString("$-1\r\n")
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "$%d\r\n", len(data)); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	_, err := w.WriteString("\r\n")
+	return err
+}
+func WriteSimple(w *bufio.Writer, s string) error {
+	_, err := w.WriteString("+" + s + "\r\n")
+	return err
+}
+func WriteError(w *bufio.Writer, s string) error {
+	_, err := w.WriteString("-" + s + "\r\n")
+	return err
+}
+func WriteInteger(w *bufio.Writer, i int64) error {
+	_, err := w.WriteString(":" + strconv.FormatInt(i, 10) + "\r\n")
+	return err
+}
+func WriteArrayHeader(w *bufio.Writer, n int) error {
+	_, err := w.WriteString("*" + strconv.Itoa(n) + "\r\n")
+	return err
+}
+
+// ReadReply reads one reply. Result can be string (simple string), int64, []byte (bulk string, nil if null),
+// []any (array, nil if null) or Error
+func ReadReply(r *bufio.Reader) (any, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, ErrProtocol
+	}
+	switch line[0] {
+	case '+':
+		return string(line[1:]), nil
+	case '-':
+		return Error(line[1:]), nil
+	case ':':
+		return strconv.ParseInt(string(line[1:]), 10, 64)
+	case '$':
+		size, err := strconv.Atoi(string(line[1:]))
+		if err != nil {
+			return nil, ErrProtocol
+		}
+		if size < 0 {
+			return []byte(nil), nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return data[:size], nil
+	case '*':
+		size, err := strconv.Atoi(string(line[1:]))
+		if err != nil {
+			return nil, ErrProtocol
+		}
+		if size < 0 {
+			return []any(nil), nil
+		}
+		result := make([]any, size)
+		for i := range result {
+			if result[i], err = ReadReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+	return nil, ErrProtocol
+}
+
+// ReadCommand reads command sent by client as an array of bulk strings
+func ReadCommand(r *bufio.Reader) ([][]byte, error) {
+	reply, err := ReadReply(r)
+	if err != nil {
+		return nil, err
+	}
+	array, ok := reply.([]any)
+	if !ok {
+		return nil, ErrProtocol
+	}
+	args := make([][]byte, len(array))
+	for i, v := range array {
+		if args[i], ok = v.([]byte); !ok {
+			return nil, ErrProtocol
+		}
+	}
+	return args, nil
+}
+func readLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadSlice('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, ErrProtocol
+	}
+	return line[:len(line)-2], nil
+}
@@ -0,0 +1,62 @@
+package freecache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+type LoaderFunc func(ctx context.Context) ([]byte, error)
+
+// GetOrLoad returns cached value or calls loader once for all concurrent callers of the same key.
+// Loaded value is cached for expireIn seconds. Loader should return ErrCacheMiss (may be wrapped)
+// when value doesn't exist, so it can be cached as negative result if WithNegativeCaching was provided.
+// Caching is best-effort: if loaded value can't be stored, it's still returned to callers.
+// Loader is shared by all waiters, so it receives ctx without cancellation: caller which gives up
+// only stops waiting, while the others still get the loaded value.
+// Loader's panic is returned to every waiter as error, since singleflight would rethrow it
+// in its own goroutine where nobody can recover it. Each caller gets its own copy of the value
+func (r *freecacherepo) GetOrLoad(ctx context.Context, key []byte, expireIn int, loader LoaderFunc) ([]byte, error) {
+	if got, err := r.Get(key); err == nil {
+		return got, nil
+	}
+	if r.negativeTTL > 0 {
+		if _, err := r.meta.Get(internalKey("negative", key)); err == nil {
+			return nil, ErrCacheMiss
+		}
+	}
+
+	result := r.loads.DoChan(string(key), func() (value any, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				value, err = nil, fmt.Errorf("cache loader panicked: %v\n%s", recovered, debug.Stack())
+			}
+		}()
+		if got, err := r.Get(key); err == nil {
+			return got, nil
+		}
+		loaded, err := loader(context.WithoutCancel(ctx))
+		if errors.Is(err, ErrCacheMiss) {
+			if r.negativeTTL > 0 {
+				_ = r.meta.Set(internalKey("negative", key), nil, r.negativeTTL)
+			}
+			return nil, ErrCacheMiss
+		}
+		if err != nil {
+			return nil, err
+		}
+		_ = r.Set(key, loaded, expireIn)
+		return loaded, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return bytes.Clone(res.Val.([]byte)), nil
+	}
+}
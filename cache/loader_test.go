@@ -0,0 +1,177 @@
+package freecache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetOrLoadDeduplication(t *testing.T) {
+	cache := NewFreeCache(0)
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	loader := func(context.Context) ([]byte, error) {
+		calls.Add(1)
+		<-release
+		return []byte("book"), nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := cache.GetOrLoad(context.Background(), []byte("key"), 0, loader)
+			assert.NoError(t, err)
+			assert.Equal(t, "book", string(got))
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.EqualValues(t, 1, calls.Load())
+
+	got, err := cache.Get([]byte("key"))
+	assert.NoError(t, err)
+	assert.Equal(t, "book", string(got))
+}
+func TestGetOrLoadErrors(t *testing.T) {
+	table := []struct {
+		Name          string
+		Options       []Option
+		LoaderError   error
+		ExceptedError error
+		ExceptedCalls int32
+	}{
+		{
+			Name:          "loader error is not cached",
+			LoaderError:   errors.New("database is down"),
+			ExceptedCalls: 2,
+		},
+		{
+			Name:          "miss without negative caching",
+			LoaderError:   ErrCacheMiss,
+			ExceptedError: ErrCacheMiss,
+			ExceptedCalls: 2,
+		},
+		{
+			Name:          "miss with negative caching",
+			Options:       []Option{WithNegativeCaching(10)},
+			LoaderError:   fmt.Errorf("book not found: %w", ErrCacheMiss),
+			ExceptedError: ErrCacheMiss,
+			ExceptedCalls: 1,
+		},
+	}
+	for _, v := range table {
+		t.Run(v.Name, func(t *testing.T) {
+			cache := NewFreeCache(0, v.Options...)
+			var calls atomic.Int32
+			loader := func(context.Context) ([]byte, error) {
+				calls.Add(1)
+				return nil, v.LoaderError
+			}
+			for i := 0; i < 2; i++ {
+				_, err := cache.GetOrLoad(context.Background(), []byte("key"), 0, loader)
+				if v.ExceptedError != nil {
+					assert.ErrorIs(t, err, v.ExceptedError)
+				} else {
+					assert.ErrorIs(t, err, v.LoaderError)
+				}
+			}
+			assert.Equal(t, v.ExceptedCalls, calls.Load())
+			assert.Zero(t, cache.EntryCount())
+		})
+	}
+}
+func TestGetOrLoadContextCanceled(t *testing.T) {
+	cache := NewFreeCache(0)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	release := make(chan struct{})
+	loader := func(ctx context.Context) ([]byte, error) {
+		select {
+		case <-release:
+			return []byte("book"), nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	canceled := make(chan error)
+	go func() {
+		_, err := cache.GetOrLoad(ctx, []byte("key"), 0, loader)
+		canceled <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	waiting := make(chan []byte)
+	go func() {
+		got, err := cache.GetOrLoad(context.Background(), []byte("key"), 0, loader)
+		assert.NoError(t, err)
+		waiting <- got
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-canceled, context.Canceled)
+
+	close(release)
+	assert.Equal(t, "book", string(<-waiting))
+}
+func TestGetOrLoadPanic(t *testing.T) {
+	cache := NewFreeCache(0)
+
+	release := make(chan struct{})
+	loader := func(context.Context) ([]byte, error) {
+		<-release
+		var book *struct{ Title []byte }
+		return book.Title, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cache.GetOrLoad(context.Background(), []byte("key"), 0, loader)
+			assert.ErrorContains(t, err, "cache loader panicked")
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Zero(t, cache.EntryCount())
+}
+func TestGetOrLoadCopies(t *testing.T) {
+	cache := NewFreeCache(0)
+
+	release := make(chan struct{})
+	loader := func(context.Context) ([]byte, error) {
+		<-release
+		return []byte("book"), nil
+	}
+
+	results := make(chan []byte, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			got, err := cache.GetOrLoad(context.Background(), []byte("key"), 0, loader)
+			assert.NoError(t, err)
+			results <- got
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	first, second := <-results, <-results
+	first[0] = 'l'
+	assert.Equal(t, "book", string(second))
+}
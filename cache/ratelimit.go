@@ -0,0 +1,131 @@
+package freecache
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+)
+
+const rateLimitKeyPrefix = "ratelimit:"
+
+// RateLimiter decides whether request identified by key can be served now.
+// If it can't, retryAfter reports when the next request is expected to be allowed
+type RateLimiter interface {
+	Allow(key string) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// tokenBucket refills bucket with rate tokens per second up to burst tokens, every request takes one token
+type tokenBucket struct {
+	store AtomicCache
+	name  string
+	rate  float64
+	burst int
+	ttl   int
+	now   func() time.Time
+}
+
+// NewTokenBucket creates limiter storing buckets in store, so every replica sharing store shares limits.
+// Name separates counters of different limiters living in the same store
+func NewTokenBucket(store AtomicCache, name string, rate float64, burst int) (*tokenBucket, error) {
+	if rate <= 0 || burst <= 0 {
+		return nil, errors.New("rate and burst must be positive")
+	}
+	return &tokenBucket{
+		store: store,
+		name:  name,
+		rate:  rate,
+		burst: burst,
+		ttl:   int(math.Ceil(float64(burst)/rate)) + 1,
+		now:   time.Now,
+	}, nil
+}
+func (b *tokenBucket) Allow(key string) (allowed bool, retryAfter time.Duration, err error) {
+	_, err = b.store.Update([]byte(rateLimitKeyPrefix+b.name+":"+key), b.ttl, func(old []byte) ([]byte, error) {
+		now := b.now()
+		tokens, last := float64(b.burst), now
+		if len(old) == 16 {
+			tokens = math.Float64frombits(binary.BigEndian.Uint64(old[:8]))
+			last = time.Unix(0, int64(binary.BigEndian.Uint64(old[8:])))
+		}
+		if elapsed := now.Sub(last); elapsed > 0 {
+			tokens = math.Min(float64(b.burst), tokens+elapsed.Seconds()*b.rate)
+		}
+		allowed, retryAfter = tokens >= 1, 0
+		if allowed {
+			tokens--
+		} else {
+			retryAfter = time.Duration((1 - tokens) / b.rate * float64(time.Second))
+		}
+		state := make([]byte, 16)
+		binary.BigEndian.PutUint64(state[:8], math.Float64bits(tokens))
+		binary.BigEndian.PutUint64(state[8:], uint64(now.UnixNano()))
+		return state, nil
+	})
+	if err != nil {
+		return false, 0, err
+	}
+	return allowed, retryAfter, nil
+}
+
+// slidingWindow allows limit requests per window. Requests of previous window are counted with weight
+// decreasing as current window goes on, which smooths bursts on windows' borders
+type slidingWindow struct {
+	store  AtomicCache
+	name   string
+	limit  int
+	window time.Duration
+	now    func() time.Time
+}
+
+func NewSlidingWindow(store AtomicCache, name string, limit int, window time.Duration) (*slidingWindow, error) {
+	if limit <= 0 || window < time.Second {
+		return nil, errors.New("limit must be positive and window must be at least one second")
+	}
+	return &slidingWindow{
+		store:  store,
+		name:   name,
+		limit:  limit,
+		window: window,
+		now:    time.Now,
+	}, nil
+}
+func (s *slidingWindow) Allow(key string) (allowed bool, retryAfter time.Duration, err error) {
+	now := s.now()
+	index := now.UnixNano() / int64(s.window)
+	elapsed := time.Duration(now.UnixNano() - index*int64(s.window))
+	prefix := rateLimitKeyPrefix + s.name + ":" + key + ":"
+
+	var previous uint64
+	if data, err := s.store.Get([]byte(prefix + strconv.FormatInt(index-1, 10))); err == nil {
+		previous, _ = binary.Uvarint(data)
+	} else if !errors.Is(err, ErrNotFound) {
+		return false, 0, err
+	}
+	weight := 1 - float64(elapsed)/float64(s.window)
+	ttl := int(math.Ceil((2 * s.window).Seconds()))
+
+	_, err = s.store.Update([]byte(prefix+strconv.FormatInt(index, 10)), ttl, func(old []byte) ([]byte, error) {
+		current, _ := binary.Uvarint(old)
+		allowed, retryAfter = float64(previous)*weight+float64(current)+1 <= float64(s.limit), 0
+		if allowed {
+			return binary.AppendUvarint(nil, current+1), nil
+		}
+		free := float64(s.limit) - 1 - float64(current)
+		if free < 0 || previous == 0 {
+			retryAfter = s.window - elapsed
+		} else {
+			// previous window's weight must drop to free/previous
+			retryAfter = time.Duration((1-free/float64(previous))*float64(s.window)) - elapsed
+		}
+		if retryAfter <= 0 {
+			retryAfter = time.Millisecond
+		}
+		return old, nil
+	})
+	if err != nil {
+		return false, 0, err
+	}
+	return allowed, retryAfter, nil
+}
@@ -0,0 +1,174 @@
+package freecache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+func (c *fakeClock) Add(d time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func rateLimitStores(t *testing.T) map[string]AtomicCache {
+	redis, _ := newTestRedis(t, "")
+	return map[string]AtomicCache{
+		"freecache": NewFreeCache(0),
+		"redis":     redis,
+	}
+}
+func TestTokenBucket(t *testing.T) {
+	for name, store := range rateLimitStores(t) {
+		t.Run(name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Unix(1000, 0)}
+			limiter, err := NewTokenBucket(store, "api", 2, 3)
+			assert.NoError(t, err)
+			limiter.now = clock.Now
+
+			for i := 0; i < 3; i++ {
+				allowed, _, err := limiter.Allow("127.0.0.1")
+				assert.NoError(t, err)
+				assert.True(t, allowed)
+			}
+			allowed, retryAfter, err := limiter.Allow("127.0.0.1")
+			assert.NoError(t, err)
+			assert.False(t, allowed)
+			assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+			allowed, _, err = limiter.Allow("127.0.0.2")
+			assert.NoError(t, err)
+			assert.True(t, allowed, "keys have separate buckets")
+
+			clock.Add(500 * time.Millisecond)
+			allowed, _, err = limiter.Allow("127.0.0.1")
+			assert.NoError(t, err)
+			assert.True(t, allowed)
+			allowed, _, err = limiter.Allow("127.0.0.1")
+			assert.NoError(t, err)
+			assert.False(t, allowed)
+		})
+	}
+}
+func TestSlidingWindow(t *testing.T) {
+	for name, store := range rateLimitStores(t) {
+		t.Run(name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Unix(1000, 0)}
+			limiter, err := NewSlidingWindow(store, "api", 4, 10*time.Second)
+			assert.NoError(t, err)
+			limiter.now = clock.Now
+
+			for i := 0; i < 4; i++ {
+				allowed, _, err := limiter.Allow("user")
+				assert.NoError(t, err)
+				assert.True(t, allowed)
+			}
+			allowed, retryAfter, err := limiter.Allow("user")
+			assert.NoError(t, err)
+			assert.False(t, allowed)
+			assert.Equal(t, 10*time.Second, retryAfter)
+
+			// previous window has weight 0.5, so only 2 more requests fit
+			clock.Add(15 * time.Second)
+			for i := 0; i < 2; i++ {
+				allowed, _, err := limiter.Allow("user")
+				assert.NoError(t, err)
+				assert.True(t, allowed)
+			}
+			allowed, retryAfter, err = limiter.Allow("user")
+			assert.NoError(t, err)
+			assert.False(t, allowed)
+			assert.Equal(t, 2500*time.Millisecond, retryAfter)
+
+			clock.Add(5 * time.Second)
+			allowed, _, err = limiter.Allow("user")
+			assert.NoError(t, err)
+			assert.True(t, allowed)
+		})
+	}
+}
+func TestRateLimiterConcurrency(t *testing.T) {
+	for name, store := range rateLimitStores(t) {
+		t.Run(name, func(t *testing.T) {
+			limiter, err := NewTokenBucket(store, "api", 0.001, 20)
+			assert.NoError(t, err)
+
+			var allowedCount atomic.Int32
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 5; j++ {
+						allowed, _, err := limiter.Allow("key")
+						assert.NoError(t, err)
+						if allowed {
+							allowedCount.Add(1)
+						}
+					}
+				}()
+			}
+			wg.Wait()
+			assert.EqualValues(t, 20, allowedCount.Load())
+		})
+	}
+}
+func TestRateLimiterValidation(t *testing.T) {
+	_, err := NewTokenBucket(NewFreeCache(0), "api", 0, 1)
+	assert.Error(t, err)
+	_, err = NewSlidingWindow(NewFreeCache(0), "api", 1, time.Millisecond)
+	assert.Error(t, err)
+}
+func TestUpdate(t *testing.T) {
+	for name, store := range rateLimitStores(t) {
+		t.Run(name, func(t *testing.T) {
+			got, err := store.Update([]byte("key"), 0, func(old []byte) ([]byte, error) {
+				assert.Nil(t, old)
+				return []byte("1"), nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, "1", string(got))
+
+			_, err = store.Update([]byte("key"), 0, func(old []byte) ([]byte, error) {
+				return nil, ErrCacheMiss
+			})
+			assert.ErrorIs(t, err, ErrCacheMiss)
+
+			got, err = store.Get([]byte("key"))
+			assert.NoError(t, err)
+			assert.Equal(t, "1", string(got))
+		})
+	}
+}
+func TestUpdateKeepsHeader(t *testing.T) {
+	cache := NewFreeCache(0)
+	assert.NoError(t, cache.SetSliding([]byte("session"), []byte("1"), 30))
+	assert.NoError(t, cache.SetWithTags([]byte("book"), []byte("1"), 0, "author"))
+
+	for _, key := range []string{"session", "book"} {
+		_, err := cache.Update([]byte(key), 5, func(old []byte) ([]byte, error) {
+			return append(old, '2'), nil
+		})
+		assert.NoError(t, err)
+	}
+
+	got, ttl, err := cache.GetWithTTL([]byte("session"))
+	assert.NoError(t, err)
+	assert.Equal(t, "12", string(got))
+	assert.Equal(t, 30, ttl)
+	assert.Equal(t, 1, cache.InvalidateTag("author"))
+}
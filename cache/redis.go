@@ -0,0 +1,249 @@
+package freecache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/reversersed/LitGO-backend-pkg/cache/internal/resp"
+)
+
+type RedisConfig struct {
+	Host     string `env:"REDIS_HOST" env-required:"true" env-description:"Redis hosting address"`
+	Port     int    `env:"REDIS_PORT" env-required:"true" env-description:"Redis port"`
+	Password string `env:"REDIS_PASS" env-description:"Redis password. If not provided, application will connect without authentication"`
+	Database int    `env:"REDIS_DB" env-default:"0" env-description:"Redis logical database index"`
+	PoolSize int    `env:"REDIS_POOL_SIZE" env-default:"10" env-description:"Maximum number of idle connections kept open"`
+}
+
+type redisConn struct {
+	net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+}
+
+func (c *redisConn) do(args ...[]byte) (any, error) {
+	if err := c.SetDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		return nil, err
+	}
+	if err := resp.WriteCommand(c.writer, args...); err != nil {
+		return nil, err
+	}
+	return resp.ReadReply(c.reader)
+}
+
+// rediscacherepo stores entries in redis, so they're shared between service replicas
+type rediscacherepo struct {
+	cfg  RedisConfig
+	idle chan *redisConn
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func NewRedisCache(cfg *RedisConfig) (*rediscacherepo, error) {
+	if cfg == nil {
+		return nil, errors.New("received nil config")
+	}
+	poolSize := cfg.PoolSize
+	if poolSize <= 0 {
+		poolSize = 1
+	}
+	repo := &rediscacherepo{
+		cfg:    *cfg,
+		idle:   make(chan *redisConn, poolSize),
+		closed: make(chan struct{}),
+	}
+	reply, err := repo.do([]byte("PING"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	if reply != "PONG" {
+		return nil, fmt.Errorf("failed to connect to redis: unexpected ping reply %v", reply)
+	}
+	return repo, nil
+}
+func (r *rediscacherepo) dial() (*redisConn, error) {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(r.cfg.Host, strconv.Itoa(r.cfg.Port)), 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	c := &redisConn{Conn: conn, reader: bufio.NewReader(conn), writer: bufio.NewWriter(conn)}
+	if r.cfg.Password != "" {
+		if err := c.expectOK([]byte("AUTH"), []byte(r.cfg.Password)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if r.cfg.Database != 0 {
+		if err := c.expectOK([]byte("SELECT"), []byte(strconv.Itoa(r.cfg.Database))); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+func (c *redisConn) expectOK(args ...[]byte) error {
+	reply, err := c.do(args...)
+	if err != nil {
+		return err
+	}
+	if e, ok := reply.(resp.Error); ok {
+		return e
+	}
+	return nil
+}
+
+// do sends single command using pooled connection. Error replies are returned as errors
+func (r *rediscacherepo) do(args ...[]byte) (any, error) {
+	conn, err := r.acquire()
+	if err != nil {
+		return nil, err
+	}
+	reply, err := conn.do(args...)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	r.release(conn)
+	if e, ok := reply.(resp.Error); ok {
+		return nil, e
+	}
+	return reply, nil
+}
+func (r *rediscacherepo) acquire() (*redisConn, error) {
+	select {
+	case <-r.closed:
+		return nil, net.ErrClosed
+	case conn := <-r.idle:
+		return conn, nil
+	default:
+		return r.dial()
+	}
+}
+func (r *rediscacherepo) release(conn *redisConn) {
+	select {
+	case <-r.closed:
+		conn.Close()
+	case r.idle <- conn:
+	default:
+		conn.Close()
+	}
+}
+func (r *rediscacherepo) Close() error {
+	r.closeOnce.Do(func() {
+		close(r.closed)
+	})
+	for {
+		select {
+		case conn := <-r.idle:
+			conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+func (r *rediscacherepo) EntryCount() int64 {
+	reply, err := r.do([]byte("DBSIZE"))
+	if err != nil {
+		return 0
+	}
+	count, _ := reply.(int64)
+	return count
+}
+func (r *rediscacherepo) Get(key []byte) ([]byte, error) {
+	reply, err := r.do([]byte("GET"), key)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected GET reply %v", reply)
+	}
+	if value == nil {
+		return nil, ErrNotFound
+	}
+	return value, nil
+}
+
+// GetWithTTL returns value with number of seconds left before it expires. Zero ttl means value never expires
+func (r *rediscacherepo) GetWithTTL(key []byte) (value []byte, ttl int, err error) {
+	conn, err := r.acquire()
+	if err != nil {
+		return nil, 0, err
+	}
+	reply, err := conn.getWithTTL(key)
+	if err != nil {
+		// connection may be left with open transaction
+		conn.Close()
+		return nil, 0, err
+	}
+	r.release(conn)
+	results, ok := reply.([]any)
+	if !ok || len(results) != 2 {
+		return nil, 0, fmt.Errorf("unexpected GET and PTTL reply %v", reply)
+	}
+	value, ok = results[0].([]byte)
+	if !ok {
+		return nil, 0, fmt.Errorf("unexpected GET reply %v", results[0])
+	}
+	if value == nil {
+		return nil, 0, ErrNotFound
+	}
+	left, ok := results[1].(int64)
+	if !ok {
+		return nil, 0, fmt.Errorf("unexpected PTTL reply %v", results[1])
+	}
+	if left <= 0 {
+		// -1 means key has no expiration, -2 that it has just expired, which is reported as the shortest ttl
+		if left == -1 {
+			return value, 0, nil
+		}
+		return value, 1, nil
+	}
+	return value, int((left + 999) / 1000), nil
+}
+
+// getWithTTL reads value and its ttl in single transaction, so ttl belongs to the returned value
+func (c *redisConn) getWithTTL(key []byte) (any, error) {
+	if err := c.expectOK([]byte("MULTI")); err != nil {
+		return nil, err
+	}
+	if err := c.expectOK([]byte("GET"), key); err != nil {
+		return nil, err
+	}
+	if err := c.expectOK([]byte("PTTL"), key); err != nil {
+		return nil, err
+	}
+	reply, err := c.do([]byte("EXEC"))
+	if err != nil {
+		return nil, err
+	}
+	if e, ok := reply.(resp.Error); ok {
+		return nil, e
+	}
+	return reply, nil
+}
+func (r *rediscacherepo) Set(key, val []byte, expireIn int) error {
+	if val == nil {
+		val = []byte{}
+	}
+	args := [][]byte{[]byte("SET"), key, val}
+	if expireIn > 0 {
+		args = append(args, []byte("EX"), []byte(strconv.Itoa(expireIn)))
+	}
+	_, err := r.do(args...)
+	return err
+}
+func (r *rediscacherepo) Delete(key []byte) (affected bool) {
+	reply, err := r.do([]byte("DEL"), key)
+	if err != nil {
+		return false
+	}
+	count, _ := reply.(int64)
+	return count > 0
+}
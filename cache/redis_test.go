@@ -0,0 +1,83 @@
+package freecache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/reversersed/LitGO-backend-pkg/cache/redistest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRedis(t *testing.T, password string) (*rediscacherepo, *redistest.Server) {
+	server, err := redistest.NewServer(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	cache, err := NewRedisCache(&RedisConfig{Host: server.Host(), Port: server.Port(), Password: password, PoolSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cache.Close() })
+	return cache, server
+}
+func TestRedisCache(t *testing.T) {
+	cache, server := newTestRedis(t, "secret")
+
+	_, err := cache.Get([]byte("1"))
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.NoError(t, cache.Set([]byte("1"), []byte("512"), 0))
+	assert.NoError(t, cache.Set([]byte("2"), []byte("1024"), 10))
+	assert.NoError(t, cache.Set([]byte("3"), nil, 0))
+	assert.EqualValues(t, 3, cache.EntryCount())
+
+	got, err := cache.Get([]byte("1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "512", string(got))
+
+	got, err = cache.Get([]byte("3"))
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	server.FastForward(11 * time.Second)
+	_, err = cache.Get([]byte("2"))
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.True(t, cache.Delete([]byte("1")))
+	assert.False(t, cache.Delete([]byte("1")))
+	assert.EqualValues(t, 1, cache.EntryCount())
+}
+func TestRedisCacheConnection(t *testing.T) {
+	server, err := redistest.NewServer("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	_, err = NewRedisCache(nil)
+	assert.Error(t, err)
+	_, err = NewRedisCache(&RedisConfig{Host: server.Host(), Port: server.Port(), Password: "wrong"})
+	assert.Error(t, err)
+	_, err = NewRedisCache(&RedisConfig{Host: server.Host(), Port: server.Port()})
+	assert.Error(t, err)
+
+	cache, err := NewRedisCache(&RedisConfig{Host: server.Host(), Port: server.Port(), Password: "secret"})
+	assert.NoError(t, err)
+	assert.NoError(t, cache.Close())
+	assert.Error(t, cache.Set([]byte("1"), []byte("1"), 0))
+}
+func TestTypedCacheOverRedis(t *testing.T) {
+	redis, _ := newTestRedis(t, "")
+	cache := NewTypedCacheFrom[string](redis, JSONCodec[typedBook]())
+
+	_, err := cache.Get("book")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+
+	book := typedBook{Name: "book", Authors: []string{"author"}}
+	assert.NoError(t, cache.Set("book", book, 0))
+	got, err := cache.Get("book")
+	assert.NoError(t, err)
+	assert.Equal(t, book, got)
+}
@@ -0,0 +1,286 @@
+// Package redistest provides in-memory server speaking redis protocol, so code using redis cache
+// can be tested without running real redis instance
+package redistest
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reversersed/LitGO-backend-pkg/cache/internal/resp"
+)
+
+type entry struct {
+	value    []byte
+	expireAt time.Time
+}
+
+type Server struct {
+	sync.Mutex
+	listener net.Listener
+	password string
+	data     map[string]entry
+	versions map[string]uint64 // changed on every write, used by WATCH
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup
+}
+
+// NewServer starts server on random local port. Server requires AUTH if password isn't empty
+func NewServer(password string) (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		listener: listener,
+		password: password,
+		data:     make(map[string]entry),
+		versions: make(map[string]uint64),
+		conns:    make(map[net.Conn]struct{}),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+func (s *Server) Host() string {
+	return s.listener.Addr().(*net.TCPAddr).IP.String()
+}
+func (s *Server) Port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+// FastForward moves server's clock, so entries expire without waiting
+func (s *Server) FastForward(d time.Duration) {
+	s.Lock()
+	defer s.Unlock()
+	for k, v := range s.data {
+		if !v.expireAt.IsZero() {
+			v.expireAt = v.expireAt.Add(-d)
+			s.data[k] = v
+		}
+	}
+}
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.Lock()
+		s.conns[conn] = struct{}{}
+		s.Unlock()
+
+		s.wg.Add(1)
+		go s.handle(conn)
+	}
+}
+func (s *Server) handle(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.Lock()
+		delete(s.conns, conn)
+		s.Unlock()
+		conn.Close()
+	}()
+
+	reader, writer := bufio.NewReader(conn), bufio.NewWriter(conn)
+	authorized := s.password == ""
+	tx := &transaction{}
+	for {
+		args, err := resp.ReadCommand(reader)
+		if err != nil || len(args) == 0 {
+			return
+		}
+		name := strings.ToUpper(string(args[0]))
+		switch {
+		case name == "AUTH":
+			if len(args) == 2 && string(args[1]) == s.password {
+				authorized = true
+				err = resp.WriteSimple(writer, "OK")
+			} else {
+				err = resp.WriteError(writer, "WRONGPASS invalid password")
+			}
+		case !authorized:
+			err = resp.WriteError(writer, "NOAUTH Authentication required.")
+		default:
+			err = s.transaction(writer, tx, name, args[1:])
+		}
+		if err == nil {
+			err = writer.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// transaction holds connection's WATCH and MULTI state
+type transaction struct {
+	watched map[string]uint64
+	queued  [][][]byte
+	multi   bool
+}
+
+func (s *Server) transaction(w *bufio.Writer, tx *transaction, name string, args [][]byte) error {
+	s.Lock()
+	defer s.Unlock()
+
+	switch name {
+	case "WATCH":
+		if tx.multi {
+			return resp.WriteError(w, "ERR WATCH inside MULTI is not allowed")
+		}
+		if tx.watched == nil {
+			tx.watched = make(map[string]uint64)
+		}
+		for _, key := range args {
+			s.get(string(key))
+			tx.watched[string(key)] = s.versions[string(key)]
+		}
+		return resp.WriteSimple(w, "OK")
+	case "UNWATCH":
+		tx.watched = nil
+		return resp.WriteSimple(w, "OK")
+	case "MULTI":
+		if tx.multi {
+			return resp.WriteError(w, "ERR MULTI calls can not be nested")
+		}
+		tx.multi = true
+		return resp.WriteSimple(w, "OK")
+	case "DISCARD":
+		if !tx.multi {
+			return resp.WriteError(w, "ERR DISCARD without MULTI")
+		}
+		*tx = transaction{}
+		return resp.WriteSimple(w, "OK")
+	case "EXEC":
+		if !tx.multi {
+			return resp.WriteError(w, "ERR EXEC without MULTI")
+		}
+		queued, watched := tx.queued, tx.watched
+		*tx = transaction{}
+		for key, version := range watched {
+			s.get(key)
+			if s.versions[key] != version {
+				_, err := w.WriteString("*-1\r\n")
+				return err
+			}
+		}
+		if err := resp.WriteArrayHeader(w, len(queued)); err != nil {
+			return err
+		}
+		for _, command := range queued {
+			if err := s.execute(w, strings.ToUpper(string(command[0])), command[1:]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if tx.multi {
+		tx.queued = append(tx.queued, append([][]byte{[]byte(name)}, args...))
+		return resp.WriteSimple(w, "QUEUED")
+	}
+	return s.execute(w, name, args)
+}
+
+// execute runs single command. Must be called with lock held
+func (s *Server) execute(w *bufio.Writer, name string, args [][]byte) error {
+	switch name {
+	case "PING":
+		return resp.WriteSimple(w, "PONG")
+	case "SELECT":
+		return resp.WriteSimple(w, "OK")
+	case "GET":
+		if len(args) != 1 {
+			return wrongArguments(w, name)
+		}
+		e, ok := s.get(string(args[0]))
+		if !ok {
+			return resp.WriteBulk(w, nil)
+		}
+		return resp.WriteBulk(w, e.value)
+	case "SET":
+		if len(args) != 2 && len(args) != 4 {
+			return wrongArguments(w, name)
+		}
+		e := entry{value: append([]byte{}, args[1]...)}
+		if len(args) == 4 {
+			seconds, err := strconv.Atoi(string(args[3]))
+			if strings.ToUpper(string(args[2])) != "EX" || err != nil || seconds <= 0 {
+				return resp.WriteError(w, "ERR syntax error")
+			}
+			e.expireAt = time.Now().Add(time.Duration(seconds) * time.Second)
+		}
+		s.data[string(args[0])] = e
+		s.versions[string(args[0])]++
+		return resp.WriteSimple(w, "OK")
+	case "PTTL":
+		if len(args) != 1 {
+			return wrongArguments(w, name)
+		}
+		e, ok := s.get(string(args[0]))
+		switch {
+		case !ok:
+			return resp.WriteInteger(w, -2)
+		case e.expireAt.IsZero():
+			return resp.WriteInteger(w, -1)
+		}
+		return resp.WriteInteger(w, time.Until(e.expireAt).Milliseconds())
+	case "DEL":
+		var deleted int64
+		for _, key := range args {
+			if _, ok := s.get(string(key)); ok {
+				delete(s.data, string(key))
+				s.versions[string(key)]++
+				deleted++
+			}
+		}
+		return resp.WriteInteger(w, deleted)
+	case "DBSIZE":
+		var count int64
+		for key := range s.data {
+			if _, ok := s.get(key); ok {
+				count++
+			}
+		}
+		return resp.WriteInteger(w, count)
+	case "FLUSHDB":
+		for key := range s.data {
+			s.versions[key]++
+		}
+		s.data = make(map[string]entry)
+		return resp.WriteSimple(w, "OK")
+	}
+	return resp.WriteError(w, "ERR unknown command '"+name+"'")
+}
+
+// get returns live entry removing it if expired. Must be called with lock held
+func (s *Server) get(key string) (entry, bool) {
+	e, ok := s.data[key]
+	if !ok {
+		return entry{}, false
+	}
+	if !e.expireAt.IsZero() && !time.Now().Before(e.expireAt) {
+		delete(s.data, key)
+		s.versions[key]++
+		return entry{}, false
+	}
+	return e, true
+}
+func wrongArguments(w *bufio.Writer, name string) error {
+	return resp.WriteError(w, "ERR wrong number of arguments for '"+strings.ToLower(name)+"' command")
+}
@@ -0,0 +1,224 @@
+package freecache
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/coocood/freecache"
+)
+
+const (
+	snapshotHeader = "litgo-cache-snapshot/2\n"
+
+	// freecache limits: keys are up to 64 kb, key and value together with 24 bytes header take
+	// at most quarter of segment, which is 1/256 of cache, and cache is at least 512 kb
+	maxKeySize      = 65535
+	entryHeaderSize = 24
+	minCacheSize    = 512 * 1024
+
+	// every snapshot entry starts with a kind telling which storage it belongs to
+	snapshotValue byte = 0
+	snapshotMeta  byte = 1
+)
+
+var ErrInvalidSnapshot = errors.New("invalid cache snapshot")
+
+// Dump writes every live entry with its remaining ttl. Entries added while dump is running may be missed
+func (r *freecacherepo) Dump(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+	if _, err := writer.WriteString(snapshotHeader); err != nil {
+		return err
+	}
+	now := time.Now().Unix()
+	if err := writeUvarint(writer, uint64(now)); err != nil {
+		return err
+	}
+
+	if err := dumpEntries(writer, r.cache, snapshotValue, now); err != nil {
+		return err
+	}
+	if err := dumpEntries(writer, r.meta, snapshotMeta, now); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// Load restores entries written by Dump. Time passed since dump is subtracted from entries' ttl,
+// so entries expired meanwhile are skipped. Returns number of restored entries, bookkeeping isn't counted
+func (r *freecacherepo) Load(reader io.Reader) (restored int, err error) {
+	buffered := bufio.NewReader(reader)
+	header := make([]byte, len(snapshotHeader))
+	if _, err := io.ReadFull(buffered, header); err != nil || string(header) != snapshotHeader {
+		return 0, ErrInvalidSnapshot
+	}
+	dumpedAt, err := binary.ReadUvarint(buffered)
+	if err != nil {
+		return 0, ErrInvalidSnapshot
+	}
+	elapsed := time.Now().Unix() - int64(dumpedAt)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	for {
+		kind, err := buffered.ReadByte()
+		if errors.Is(err, io.EOF) {
+			return restored, nil
+		}
+		if err != nil {
+			return restored, err
+		}
+		storage, limit := r.cache, maxEntrySize(r.size)
+		switch kind {
+		case snapshotValue:
+		case snapshotMeta:
+			storage, limit = r.meta, maxEntrySize(r.size/metaSizeRatio)
+		default:
+			return restored, ErrInvalidSnapshot
+		}
+		key, err := readBytes(buffered, min(limit, maxKeySize))
+		if err != nil {
+			return restored, unexpectedEOF(err)
+		}
+		value, err := readBytes(buffered, limit-len(key))
+		if err != nil {
+			return restored, unexpectedEOF(err)
+		}
+		ttl, err := binary.ReadUvarint(buffered)
+		if err != nil {
+			return restored, unexpectedEOF(err)
+		}
+		expireIn := 0
+		if ttl != 0 {
+			if int64(ttl) <= elapsed {
+				continue
+			}
+			expireIn = int(int64(ttl) - elapsed)
+		}
+		if err := storage.Set(key, value, expireIn); err != nil {
+			return restored, err
+		}
+		if kind == snapshotValue {
+			restored++
+		}
+	}
+}
+
+// DumpFile writes snapshot to path atomically, so a crash during dump doesn't corrupt previous snapshot
+func (r *freecacherepo) DumpFile(path string) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if err := r.Dump(temp); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
+
+// LoadFile restores snapshot from path. Missing file isn't an error, since there's nothing to warm up on the first start
+func (r *freecacherepo) LoadFile(path string) (restored int, err error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	return r.Load(file)
+}
+
+type snapshotCloser struct {
+	cache *freecacherepo
+	path  string
+}
+
+func (s *snapshotCloser) Close() error {
+	if err := s.cache.DumpFile(s.path); err != nil {
+		return fmt.Errorf("unable to dump cache to %s: %w", s.path, err)
+	}
+	return nil
+}
+
+// DumpOnClose returns closer which dumps cache to path, e.g. shutdown.Graceful(cache.DumpOnClose("cache.snapshot"))
+func (r *freecacherepo) DumpOnClose(path string) io.Closer {
+	return &snapshotCloser{cache: r, path: path}
+}
+func dumpEntries(w *bufio.Writer, storage *freecache.Cache, kind byte, now int64) error {
+	iterator := storage.NewIterator()
+	for entry := iterator.Next(); entry != nil; entry = iterator.Next() {
+		var ttl uint64
+		if entry.ExpireAt != 0 {
+			if int64(entry.ExpireAt) <= now {
+				continue
+			}
+			ttl = uint64(int64(entry.ExpireAt) - now)
+		}
+		if err := w.WriteByte(kind); err != nil {
+			return err
+		}
+		if err := writeBytes(w, entry.Key); err != nil {
+			return err
+		}
+		if err := writeBytes(w, entry.Value); err != nil {
+			return err
+		}
+		if err := writeUvarint(w, ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func writeUvarint(w *bufio.Writer, value uint64) error {
+	_, err := w.Write(binary.AppendUvarint(nil, value))
+	return err
+}
+func writeBytes(w *bufio.Writer, data []byte) error {
+	if err := writeUvarint(w, uint64(len(data))); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// maxEntrySize returns the largest total length of key and value freecache of size accepts
+func maxEntrySize(size int) int {
+	return max(size, minCacheSize)/1024 - entryHeaderSize
+}
+
+// readBytes reads length-prefixed data. Length is checked against limit before allocation,
+// so corrupted snapshot can't make Load allocate more than a single entry takes
+func readBytes(r *bufio.Reader, limit int) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if size > uint64(limit) {
+		return nil, ErrInvalidSnapshot
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return data, nil
+}
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
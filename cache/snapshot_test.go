@@ -0,0 +1,79 @@
+package freecache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDumpAndLoad(t *testing.T) {
+	cache := NewFreeCache(0)
+	assert.NoError(t, cache.Set([]byte("1"), []byte("forever"), 0))
+	assert.NoError(t, cache.Set([]byte("2"), []byte("minute"), 60))
+	assert.NoError(t, cache.SetWithTags([]byte("3"), []byte("tagged"), 0, "author"))
+
+	var buf bytes.Buffer
+	assert.NoError(t, cache.Dump(&buf))
+
+	restored := NewFreeCache(0)
+	count, err := restored.Load(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	got, err := restored.Get([]byte("1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "forever", string(got))
+
+	ttl, err := restored.cache.TTL([]byte("2"))
+	assert.NoError(t, err)
+	assert.InDelta(t, 60, ttl, 1)
+
+	assert.Equal(t, 1, restored.InvalidateTag("author"))
+}
+func TestLoadInvalidSnapshot(t *testing.T) {
+	cache := NewFreeCache(0)
+	assert.NoError(t, cache.Set([]byte("key"), []byte("value"), 0))
+	var buf bytes.Buffer
+	assert.NoError(t, cache.Dump(&buf))
+
+	_, err := NewFreeCache(0).Load(bytes.NewReader([]byte("not a snapshot")))
+	assert.ErrorIs(t, err, ErrInvalidSnapshot)
+
+	_, err = NewFreeCache(0).Load(bytes.NewReader(buf.Bytes()[:buf.Len()-3]))
+	assert.Error(t, err)
+
+	corrupted := binary.AppendUvarint([]byte(snapshotHeader), uint64(time.Now().Unix()))
+	corrupted = append(corrupted, snapshotValue)
+	corrupted = binary.AppendUvarint(corrupted, 3)
+	corrupted = append(corrupted, "key"...)
+	corrupted = binary.AppendUvarint(corrupted, 1<<30)
+	_, err = NewFreeCache(0).Load(bytes.NewReader(corrupted))
+	assert.ErrorIs(t, err, ErrInvalidSnapshot, "value length exceeds entry size")
+}
+func TestSnapshotFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+
+	count, err := NewFreeCache(0).LoadFile(path)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	cache := NewFreeCache(0)
+	assert.NoError(t, cache.Set([]byte("key"), []byte("value"), 0))
+	assert.NoError(t, cache.DumpOnClose(path).Close())
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	restored := NewFreeCache(0)
+	count, err = restored.LoadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	assert.Error(t, cache.DumpOnClose(filepath.Join(path, "missing", "dir")).Close())
+}
@@ -0,0 +1,103 @@
+package freecache
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+type Stats struct {
+	EntryCount        int64
+	HitCount          int64
+	MissCount         int64
+	LookupCount       int64
+	HitRate           float64
+	EvacuateCount     int64 // entries evicted because cache was full
+	ExpiredCount      int64
+	OverwriteCount    int64
+	TouchedCount      int64
+	AverageAccessTime int64 // unix seconds, average of entries' last access time
+}
+
+type StatsSource interface {
+	Stats() Stats
+}
+
+type Logger interface {
+	Infof(string, ...any)
+}
+
+func (r *freecacherepo) Stats() Stats {
+	return Stats{
+		EntryCount:        r.cache.EntryCount(),
+		HitCount:          r.cache.HitCount(),
+		MissCount:         r.cache.MissCount(),
+		LookupCount:       r.cache.LookupCount(),
+		HitRate:           r.cache.HitRate(),
+		EvacuateCount:     r.cache.EvacuateCount(),
+		ExpiredCount:      r.cache.ExpiredCount(),
+		OverwriteCount:    r.cache.OverwriteCount(),
+		TouchedCount:      r.cache.TouchedCount(),
+		AverageAccessTime: r.cache.AverageAccessTime(),
+	}
+}
+
+// ReportStats logs cache statistics every interval until ctx is done. Should be started in its own goroutine
+func ReportStats(ctx context.Context, name string, source StatsSource, interval time.Duration, logger Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s := source.Stats()
+			logger.Infof("cache %s stats: entries = %d, hits = %d, misses = %d, hit rate = %.3f, evacuated = %d, expired = %d, overwritten = %d",
+				name, s.EntryCount, s.HitCount, s.MissCount, s.HitRate, s.EvacuateCount, s.ExpiredCount, s.OverwriteCount)
+		}
+	}
+}
+
+// WritePrometheus writes stats in prometheus text exposition format. Metric names start with namespace
+func WritePrometheus(w io.Writer, namespace string, s Stats) error {
+	metrics := []struct {
+		name  string
+		kind  string
+		help  string
+		value any
+	}{
+		{"cache_entries", "gauge", "Number of entries in the cache", s.EntryCount},
+		{"cache_hits_total", "counter", "Number of cache hits", s.HitCount},
+		{"cache_misses_total", "counter", "Number of cache misses", s.MissCount},
+		{"cache_lookups_total", "counter", "Number of cache lookups", s.LookupCount},
+		{"cache_hit_ratio", "gauge", "Ratio of hits to lookups", s.HitRate},
+		{"cache_evacuations_total", "counter", "Number of entries evicted because cache was full", s.EvacuateCount},
+		{"cache_expirations_total", "counter", "Number of expired entries", s.ExpiredCount},
+		{"cache_overwrites_total", "counter", "Number of entries overwritten by set", s.OverwriteCount},
+		{"cache_touches_total", "counter", "Number of entries which expiration was updated", s.TouchedCount},
+		{"cache_average_access_timestamp_seconds", "gauge", "Average unix time of entries' last access", s.AverageAccessTime},
+	}
+	for _, m := range metrics {
+		name := m.name
+		if namespace != "" {
+			name = namespace + "_" + name
+		}
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", name, m.help, name, m.kind, name, m.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PrometheusHandler serves stats of source for prometheus scrapper
+func PrometheusHandler(namespace string, source StatsSource) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := WritePrometheus(w, namespace, source.Stats()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
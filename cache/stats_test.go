@@ -0,0 +1,77 @@
+package freecache
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type statsLogger struct {
+	sync.Mutex
+	lines []string
+}
+
+func (l *statsLogger) Infof(format string, args ...any) {
+	l.Lock()
+	defer l.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+func TestStats(t *testing.T) {
+	cache := NewFreeCache(0)
+	assert.NoError(t, cache.Set([]byte("1"), []byte("1"), 0))
+	assert.NoError(t, cache.Set([]byte("1"), []byte("2"), 0))
+	_, _ = cache.Get([]byte("1"))
+	_, _ = cache.Get([]byte("2"))
+	_, _ = cache.Get([]byte("3"))
+
+	stats := cache.Stats()
+	assert.EqualValues(t, 1, stats.EntryCount)
+	assert.EqualValues(t, 1, stats.HitCount)
+	assert.EqualValues(t, 2, stats.MissCount)
+	assert.EqualValues(t, 3, stats.LookupCount)
+	assert.EqualValues(t, 1, stats.OverwriteCount)
+	assert.InDelta(t, 1.0/3.0, stats.HitRate, 0.001)
+}
+func TestReportStats(t *testing.T) {
+	cache := NewFreeCache(0)
+	logger := &statsLogger{}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		ReportStats(ctx, "books", cache, 10*time.Millisecond, logger)
+		close(done)
+	}()
+	time.Sleep(35 * time.Millisecond)
+	cancel()
+	<-done
+
+	logger.Lock()
+	defer logger.Unlock()
+	if assert.NotEmpty(t, logger.lines) {
+		assert.Contains(t, logger.lines[0], "cache books stats")
+	}
+}
+func TestPrometheusHandler(t *testing.T) {
+	cache := NewFreeCache(0)
+	assert.NoError(t, cache.Set([]byte("1"), []byte("1"), 0))
+	_, _ = cache.Get([]byte("1"))
+
+	w := httptest.NewRecorder()
+	PrometheusHandler("books", cache).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "# TYPE books_cache_hits_total counter\nbooks_cache_hits_total 1\n")
+	assert.Contains(t, body, "books_cache_entries 1\n")
+	assert.Contains(t, body, "books_cache_hit_ratio 1\n")
+	assert.Equal(t, 30, strings.Count(body, "\n"))
+}
@@ -0,0 +1,52 @@
+package freecache
+
+import "bytes"
+
+// SetWithTags stores value and remembers its tags, so it can be removed by InvalidateTag.
+// Tags are kept in value's header, so they live exactly as long as the value and can't be evicted apart from it.
+// Tagged write is a single Set and doesn't take any locks of its own
+func (r *freecacherepo) SetWithTags(key, val []byte, expireIn int, tags ...string) error {
+	return r.set(key, val, expireIn, valueHeader{tags: encodeTags(tags)})
+}
+
+// InvalidateTag deletes every entry stored with tag and returns number of deleted entries.
+// Entries changed by plain Set after being tagged are left untouched.
+// It walks through the whole cache, so it shouldn't be called on hot paths
+func (r *freecacherepo) InvalidateTag(tag string) (deleted int) {
+	var keys [][]byte
+	iterator := r.cache.NewIterator()
+	for entry := iterator.Next(); entry != nil; entry = iterator.Next() {
+		if _, header := decodeValue(entry.Value); header.hasTag(tag) {
+			keys = append(keys, entry.Key)
+		}
+	}
+	for _, key := range keys {
+		// key could be overwritten since it was visited
+		stored, err := r.cache.Peek(key)
+		if err != nil {
+			continue
+		}
+		if _, header := decodeValue(stored); header.hasTag(tag) && r.Delete(key) {
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// DeletePrefix deletes every entry which key starts with prefix and returns number of deleted entries.
+// It walks through the whole cache, so it shouldn't be called on hot paths
+func (r *freecacherepo) DeletePrefix(prefix []byte) (deleted int) {
+	var keys [][]byte
+	iterator := r.cache.NewIterator()
+	for entry := iterator.Next(); entry != nil; entry = iterator.Next() {
+		if bytes.HasPrefix(entry.Key, prefix) {
+			keys = append(keys, entry.Key)
+		}
+	}
+	for _, key := range keys {
+		if r.cache.Del(key) {
+			deleted++
+		}
+	}
+	return deleted
+}
@@ -0,0 +1,109 @@
+package freecache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvalidateTag(t *testing.T) {
+	cache := NewFreeCache(0)
+
+	assert.NoError(t, cache.SetWithTags([]byte("book:1"), []byte("1"), 0, "author:1", "genre:1"))
+	assert.NoError(t, cache.SetWithTags([]byte("book:2"), []byte("2"), 60, "author:1"))
+	assert.NoError(t, cache.SetWithTags([]byte("book:2"), []byte("2"), 60, "author:1"))
+	assert.NoError(t, cache.SetWithTags([]byte("book:3"), []byte("3"), 0, "author:2", "genre:1"))
+	assert.NoError(t, cache.Set([]byte("search:1"), []byte("1"), 0))
+
+	assert.Equal(t, 2, cache.InvalidateTag("author:1"))
+	assert.Equal(t, 0, cache.InvalidateTag("author:1"))
+	assert.Equal(t, 0, cache.InvalidateTag("unknown"))
+
+	_, err := cache.Get([]byte("book:1"))
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = cache.Get([]byte("book:2"))
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = cache.Get([]byte("book:3"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, cache.InvalidateTag("genre:1"))
+	_, err = cache.Get([]byte("search:1"))
+	assert.NoError(t, err)
+}
+func TestTagsOverwrite(t *testing.T) {
+	cache := NewFreeCache(0)
+
+	assert.NoError(t, cache.SetWithTags([]byte("1"), []byte("1"), 10, "tag"))
+	assert.NoError(t, cache.SetWithTags([]byte("2"), []byte("2"), 0, "tag"))
+	assert.NoError(t, cache.SetWithTags([]byte("3"), []byte("3"), 0, "tag", "other"))
+	assert.NoError(t, cache.SetSliding([]byte("4"), []byte("4"), 30))
+
+	assert.NoError(t, cache.Set([]byte("2"), []byte("changed"), 0))
+	assert.NoError(t, cache.SetWithTags([]byte("3"), []byte("changed"), 0, "other"))
+	assert.True(t, cache.Delete([]byte("1")))
+	assert.Equal(t, 0, cache.InvalidateTag("tag"))
+	assert.Equal(t, 0, cache.InvalidateTag("ta"))
+
+	got, err := cache.Get([]byte("2"))
+	assert.NoError(t, err)
+	assert.Equal(t, "changed", string(got))
+	assert.Equal(t, 1, cache.InvalidateTag("other"))
+}
+func TestInvalidateLargeTag(t *testing.T) {
+	cache := NewFreeCache(0)
+
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, cache.SetWithTags([]byte(fmt.Sprintf("book:%d", i)), []byte("1"), 0, "author"))
+	}
+	assert.EqualValues(t, 1000, cache.EntryCount())
+	assert.Equal(t, 1000, cache.InvalidateTag("author"))
+	assert.EqualValues(t, 0, cache.EntryCount())
+}
+func TestInvalidateTagWithFullBookkeeping(t *testing.T) {
+	cache := NewFreeCache(8<<20, WithNegativeCaching(60))
+
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, cache.SetWithTags([]byte(fmt.Sprintf("book:%d", i)), []byte("1"), 0, "author:1"))
+	}
+	loader := func(context.Context) ([]byte, error) {
+		return nil, ErrCacheMiss
+	}
+	for i := 0; i < 50000; i++ {
+		_, err := cache.GetOrLoad(context.Background(), []byte(fmt.Sprintf("missing:%d", i)), 0, loader)
+		assert.ErrorIs(t, err, ErrCacheMiss)
+	}
+	assert.NotZero(t, cache.meta.EvacuateCount())
+
+	assert.Equal(t, 100, cache.InvalidateTag("author:1"))
+	assert.EqualValues(t, 0, cache.EntryCount())
+}
+func TestSetWithTagsTooLarge(t *testing.T) {
+	cache := NewFreeCache(0)
+
+	// tags are stored with value, so they count towards entry size
+	tag := strings.Repeat("t", 600)
+	assert.Error(t, cache.SetWithTags([]byte("book"), []byte("1"), 0, "author", tag))
+	_, err := cache.Get([]byte("book"))
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+func TestDeletePrefix(t *testing.T) {
+	cache := NewFreeCache(0)
+
+	for _, key := range []string{"book:1", "book:2", "book:3", "genre:1", "bookmark:1"} {
+		assert.NoError(t, cache.Set([]byte(key), []byte("1"), 0))
+	}
+	assert.NoError(t, cache.SetWithTags([]byte("book:4"), []byte("1"), 0, "author"))
+
+	assert.Equal(t, 4, cache.DeletePrefix([]byte("book:")))
+	assert.Equal(t, 0, cache.DeletePrefix([]byte("book:")))
+
+	_, err := cache.Get([]byte("bookmark:1"))
+	assert.NoError(t, err)
+	_, err = cache.Get([]byte("genre:1"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, cache.DeletePrefix(nil))
+	assert.Equal(t, 0, cache.InvalidateTag("author"))
+}
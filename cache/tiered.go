@@ -0,0 +1,163 @@
+package freecache
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"sync/atomic"
+)
+
+// Broadcaster delivers messages to every subscribed service replica.
+// rabbitmq package provides implementation over fanout exchange
+type Broadcaster interface {
+	Publish(message []byte) error
+	Subscribe(handler func(message []byte)) error
+}
+
+// ttlCache reports remaining ttl along with value, so local copy doesn't outlive remote entry
+type ttlCache interface {
+	GetWithTTL(key []byte) (value []byte, ttl int, err error)
+}
+
+const (
+	instanceIdLength = 16
+	// invalidation counters are striped by key hash, so changes of other keys rarely stop read-through
+	invalidationStripes = 256
+)
+
+// TieredCache keeps short-living copies of remote entries in local freecache.
+// Local copies are dropped on every replica when key is changed or deleted through any of them
+type TieredCache struct {
+	local      *freecacherepo
+	remote     Cache
+	localTTL   int
+	bus        Broadcaster
+	instanceId []byte
+
+	failedInvalidations atomic.Int64
+	invalidations       [invalidationStripes]atomic.Uint64
+	seed                maphash.Seed
+}
+
+// NewTieredCache creates two-level cache. Local entries live at most localTTL seconds.
+// Bus can be nil if service runs as a single replica
+func NewTieredCache(local *freecacherepo, remote Cache, localTTL int, bus Broadcaster) (*TieredCache, error) {
+	if local == nil || remote == nil {
+		return nil, errors.New("both local and remote caches must be provided")
+	}
+	if localTTL <= 0 {
+		return nil, errors.New("local ttl must be positive, otherwise local entries will never be refreshed")
+	}
+	instanceId := make([]byte, instanceIdLength)
+	if _, err := rand.Read(instanceId); err != nil {
+		return nil, err
+	}
+	c := &TieredCache{
+		local:      local,
+		remote:     remote,
+		localTTL:   localTTL,
+		bus:        bus,
+		instanceId: instanceId,
+		seed:       maphash.MakeSeed(),
+	}
+	if bus != nil {
+		if err := bus.Subscribe(c.handleInvalidation); err != nil {
+			return nil, fmt.Errorf("unable to subscribe to invalidations: %w", err)
+		}
+	}
+	return c, nil
+}
+func (c *TieredCache) EntryCount() int64 {
+	return c.remote.EntryCount()
+}
+func (c *TieredCache) Get(key []byte) ([]byte, error) {
+	if got, err := c.local.Get(key); err == nil {
+		return got, nil
+	}
+	generation := c.invalidationCounter(key).Load()
+	if remote, ok := c.remote.(ttlCache); ok {
+		got, ttl, err := remote.GetWithTTL(key)
+		if err != nil {
+			return nil, err
+		}
+		c.readThrough(key, got, c.localExpiration(ttl), generation)
+		return got, nil
+	}
+	got, err := c.remote.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	c.readThrough(key, got, c.localTTL, generation)
+	return got, nil
+}
+
+// Set stores value in remote store and notifies other replicas. Value is considered stored even if notification
+// fails, so such failures are only counted by FailedInvalidations: other replicas refresh their copies in localTTL anyway
+func (c *TieredCache) Set(key, val []byte, expireIn int) error {
+	if err := c.remote.Set(key, val, expireIn); err != nil {
+		return err
+	}
+	_ = c.local.Set(key, val, c.localExpiration(expireIn))
+	c.invalidate(key)
+	return nil
+}
+
+// Delete removes key from remote store, local cache and local caches of other replicas.
+// Returned value reports whether the key existed in remote store
+func (c *TieredCache) Delete(key []byte) (affected bool) {
+	affected = c.remote.Delete(key)
+	c.local.Delete(key)
+	c.invalidate(key)
+	return affected
+}
+
+// FailedInvalidations returns number of changes other replicas weren't notified about because publishing failed
+func (c *TieredCache) FailedInvalidations() int64 {
+	return c.failedInvalidations.Load()
+}
+
+// localExpiration limits local copy lifetime by remote entry's ttl
+func (c *TieredCache) localExpiration(remoteTTL int) int {
+	if remoteTTL > 0 && remoteTTL < c.localTTL {
+		return remoteTTL
+	}
+	return c.localTTL
+}
+func (c *TieredCache) invalidate(key []byte) {
+	if c.bus == nil {
+		return
+	}
+	message := make([]byte, 0, instanceIdLength+len(key))
+	message = append(message, c.instanceId...)
+	message = append(message, key...)
+	if err := c.bus.Publish(message); err != nil {
+		c.failedInvalidations.Add(1)
+	}
+}
+func (c *TieredCache) handleInvalidation(message []byte) {
+	if len(message) < instanceIdLength || bytes.Equal(message[:instanceIdLength], c.instanceId) {
+		return
+	}
+	key := message[instanceIdLength:]
+	c.invalidationCounter(key).Add(1)
+	c.local.Delete(key)
+}
+
+// readThrough stores value read from remote store locally unless key was invalidated since generation was read,
+// otherwise value changed by another replica meanwhile would be served until local copy expires
+func (c *TieredCache) readThrough(key, val []byte, expireIn int, generation uint64) {
+	counter := c.invalidationCounter(key)
+	if counter.Load() != generation {
+		return
+	}
+	_ = c.local.Set(key, val, expireIn)
+	// invalidation could arrive between the check and Set without seeing the copy
+	if counter.Load() != generation {
+		c.local.Delete(key)
+	}
+}
+func (c *TieredCache) invalidationCounter(key []byte) *atomic.Uint64 {
+	return &c.invalidations[maphash.Bytes(c.seed, key)%invalidationStripes]
+}
@@ -0,0 +1,166 @@
+package freecache
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryBus struct {
+	sync.Mutex
+	handlers []func([]byte)
+	fail     bool
+}
+
+func (b *memoryBus) Publish(message []byte) error {
+	b.Lock()
+	defer b.Unlock()
+	if b.fail {
+		return errors.New("broker is down")
+	}
+	for _, h := range b.handlers {
+		h(message)
+	}
+	return nil
+}
+func (b *memoryBus) Subscribe(handler func([]byte)) error {
+	b.Lock()
+	defer b.Unlock()
+	b.handlers = append(b.handlers, handler)
+	return nil
+}
+
+// readHookCache calls afterRead once value is read, emulating changes made while it's in flight
+type readHookCache struct {
+	Cache
+	afterRead func()
+}
+
+func (c *readHookCache) Get(key []byte) ([]byte, error) {
+	got, err := c.Cache.Get(key)
+	if c.afterRead != nil {
+		c.afterRead()
+	}
+	return got, err
+}
+
+func TestTieredCacheReadThrough(t *testing.T) {
+	remote := NewFreeCache(0)
+	local := NewFreeCache(0)
+	cache, err := NewTieredCache(local, remote, 5, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = cache.Get([]byte("book"))
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.NoError(t, remote.Set([]byte("book"), []byte("remote"), 0))
+	got, err := cache.Get([]byte("book"))
+	assert.NoError(t, err)
+	assert.Equal(t, "remote", string(got))
+
+	got, err = local.Get([]byte("book"))
+	assert.NoError(t, err)
+	assert.Equal(t, "remote", string(got))
+
+	ttl, err := local.cache.TTL([]byte("book"))
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, ttl, uint32(5))
+}
+func TestTieredCacheInvalidation(t *testing.T) {
+	remote := NewFreeCache(0)
+	bus := &memoryBus{}
+	first, err := NewTieredCache(NewFreeCache(0), remote, 60, bus)
+	assert.NoError(t, err)
+	second, err := NewTieredCache(NewFreeCache(0), remote, 60, bus)
+	assert.NoError(t, err)
+
+	assert.NoError(t, first.Set([]byte("book"), []byte("v1"), 0))
+	got, err := second.Get([]byte("book"))
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", string(got))
+
+	assert.NoError(t, first.Set([]byte("book"), []byte("v2"), 0))
+	got, err = second.Get([]byte("book"))
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", string(got))
+	_, err = first.local.Get([]byte("book"))
+	assert.NoError(t, err, "writer keeps its own local copy")
+
+	assert.True(t, second.Delete([]byte("book")))
+	_, err = first.Get([]byte("book"))
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.False(t, first.Delete([]byte("book")))
+}
+func TestTieredCacheErrors(t *testing.T) {
+	_, err := NewTieredCache(nil, NewFreeCache(0), 5, nil)
+	assert.Error(t, err)
+	_, err = NewTieredCache(NewFreeCache(0), NewFreeCache(0), 0, nil)
+	assert.Error(t, err)
+
+	bus := &memoryBus{fail: true}
+	cache, err := NewTieredCache(NewFreeCache(0), NewFreeCache(0), 5, bus)
+	assert.NoError(t, err)
+	assert.NoError(t, cache.Set([]byte("book"), []byte("1"), 0))
+	assert.True(t, cache.Delete([]byte("book")))
+	assert.EqualValues(t, 2, cache.FailedInvalidations())
+}
+func TestTieredCacheRemoteTTL(t *testing.T) {
+	table := []struct {
+		Name       string
+		Remote     func(t *testing.T) Cache
+		ExpireIn   int
+		ExceptedLE uint32
+		ExceptedGE uint32
+	}{
+		{Name: "freecache remote expires before local ttl", Remote: func(*testing.T) Cache { return NewFreeCache(0) }, ExpireIn: 3, ExceptedGE: 2, ExceptedLE: 3},
+		{Name: "redis remote expires before local ttl", Remote: func(t *testing.T) Cache { r, _ := newTestRedis(t, ""); return r }, ExpireIn: 3, ExceptedGE: 2, ExceptedLE: 3},
+		{Name: "remote lives longer", Remote: func(*testing.T) Cache { return NewFreeCache(0) }, ExpireIn: 600, ExceptedGE: 59, ExceptedLE: 60},
+		{Name: "remote never expires", Remote: func(t *testing.T) Cache { r, _ := newTestRedis(t, ""); return r }, ExpireIn: 0, ExceptedGE: 59, ExceptedLE: 60},
+	}
+	for _, v := range table {
+		t.Run(v.Name, func(t *testing.T) {
+			remote := v.Remote(t)
+			local := NewFreeCache(0)
+			cache, err := NewTieredCache(local, remote, 60, nil)
+			assert.NoError(t, err)
+
+			assert.NoError(t, remote.Set([]byte("book"), []byte("remote"), v.ExpireIn))
+			got, err := cache.Get([]byte("book"))
+			assert.NoError(t, err)
+			assert.Equal(t, "remote", string(got))
+
+			ttl, err := local.cache.TTL([]byte("book"))
+			assert.NoError(t, err)
+			assert.GreaterOrEqual(t, ttl, v.ExceptedGE)
+			assert.LessOrEqual(t, ttl, v.ExceptedLE)
+		})
+	}
+}
+func TestTieredCacheInvalidationDuringRead(t *testing.T) {
+	remote := NewFreeCache(0)
+	bus := &memoryBus{}
+	hooked := &readHookCache{Cache: remote}
+	reader, err := NewTieredCache(NewFreeCache(0), hooked, 60, bus)
+	assert.NoError(t, err)
+	writer, err := NewTieredCache(NewFreeCache(0), remote, 60, bus)
+	assert.NoError(t, err)
+
+	assert.NoError(t, remote.Set([]byte("book"), []byte("v1"), 0))
+	hooked.afterRead = func() {
+		hooked.afterRead = nil
+		assert.NoError(t, writer.Set([]byte("book"), []byte("v2"), 0))
+	}
+	got, err := reader.Get([]byte("book"))
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", string(got))
+
+	_, err = reader.local.Get([]byte("book"))
+	assert.ErrorIs(t, err, ErrNotFound)
+	got, err = reader.Get([]byte("book"))
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", string(got))
+}
@@ -0,0 +1,56 @@
+package freecache
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrCacheMiss = errors.New("cache: key not found")
+
+type Key interface {
+	~string | ~[]byte
+}
+
+type TypedCache[K Key, V any] struct {
+	cache Cache
+	codec Codec[V]
+}
+
+func NewTypedCache[K Key, V any](size int, codec Codec[V]) *TypedCache[K, V] {
+	return NewTypedCacheFrom[K](NewFreeCache(size), codec)
+}
+
+// NewTypedCacheFrom wraps any cache implementation, e.g. one created by NewRedisCache
+func NewTypedCacheFrom[K Key, V any](cache Cache, codec Codec[V]) *TypedCache[K, V] {
+	return &TypedCache[K, V]{cache: cache, codec: codec}
+}
+func (c *TypedCache[K, V]) EntryCount() int64 {
+	return c.cache.EntryCount()
+}
+
+// Get returns ErrCacheMiss if key is absent or expired
+func (c *TypedCache[K, V]) Get(key K) (V, error) {
+	var zero V
+	data, err := c.cache.Get([]byte(key))
+	if errors.Is(err, ErrNotFound) {
+		return zero, ErrCacheMiss
+	}
+	if err != nil {
+		return zero, err
+	}
+	value, err := c.codec.Unmarshal(data)
+	if err != nil {
+		return zero, fmt.Errorf("unable to decode cached value: %w", err)
+	}
+	return value, nil
+}
+func (c *TypedCache[K, V]) Set(key K, value V, expireIn int) error {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("unable to encode value: %w", err)
+	}
+	return c.cache.Set([]byte(key), data, expireIn)
+}
+func (c *TypedCache[K, V]) Delete(key K) (affected bool) {
+	return c.cache.Delete([]byte(key))
+}
@@ -0,0 +1,66 @@
+package freecache
+
+import (
+	"testing"
+
+	shared_pb "github.com/reversersed/LitGO-proto/gen/go/shared"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+type typedBook struct {
+	Name    string
+	Authors []string
+	Rating  float64
+}
+
+func TestTypedCacheCodecs(t *testing.T) {
+	book := typedBook{Name: "book", Authors: []string{"first", "second"}, Rating: 4.5}
+
+	t.Run("json codec", func(t *testing.T) {
+		cache := NewTypedCache[string](0, JSONCodec[typedBook]())
+		assert.NoError(t, cache.Set("book", book, 0))
+
+		got, err := cache.Get("book")
+		assert.NoError(t, err)
+		assert.Equal(t, book, got)
+	})
+	t.Run("gob codec", func(t *testing.T) {
+		cache := NewTypedCache[[]byte](0, GobCodec[typedBook]())
+		assert.NoError(t, cache.Set([]byte("book"), book, 0))
+
+		got, err := cache.Get([]byte("book"))
+		assert.NoError(t, err)
+		assert.Equal(t, book, got)
+	})
+	t.Run("proto codec", func(t *testing.T) {
+		cache := NewTypedCache[string](0, ProtoCodec[*shared_pb.ErrorDetail]())
+		detail := &shared_pb.ErrorDetail{Field: "name", Description: "required"}
+		assert.NoError(t, cache.Set("detail", detail, 0))
+
+		got, err := cache.Get("detail")
+		assert.NoError(t, err)
+		assert.True(t, proto.Equal(detail, got))
+	})
+}
+func TestTypedCacheMiss(t *testing.T) {
+	cache := NewTypedCache[string](0, JSONCodec[typedBook]())
+
+	_, err := cache.Get("missing")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+
+	assert.NoError(t, cache.Set("book", typedBook{Name: "book"}, 0))
+	assert.EqualValues(t, 1, cache.EntryCount())
+	assert.True(t, cache.Delete("book"))
+
+	_, err = cache.Get("book")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+}
+func TestTypedCacheDecodeError(t *testing.T) {
+	cache := NewTypedCache[string](0, JSONCodec[typedBook]())
+	assert.NoError(t, cache.cache.Set([]byte("book"), []byte("not a json"), 0))
+
+	_, err := cache.Get("book")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrCacheMiss)
+}
@@ -0,0 +1,116 @@
+package freecache
+
+import (
+	"errors"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/reversersed/LitGO-backend-pkg/cache/internal/resp"
+)
+
+// UpdateFunc receives current value (nil if key is absent) and returns the new one
+type UpdateFunc func(old []byte) ([]byte, error)
+
+// AtomicCache can change stored value without interference of concurrent writers,
+// including writers from other service replicas when store is shared
+type AtomicCache interface {
+	Cache
+	// Update stores result of fn for expireIn seconds. If fn returns error, value is left untouched
+	Update(key []byte, expireIn int, fn UpdateFunc) ([]byte, error)
+}
+
+var ErrUpdateConflict = errors.New("cache: value was concurrently modified too many times")
+
+var (
+	_ AtomicCache = (*freecacherepo)(nil)
+	_ AtomicCache = (*rediscacherepo)(nil)
+)
+
+// Update keeps value's header, so updated value still slides and stays tagged if it did before
+func (r *freecacherepo) Update(key []byte, expireIn int, fn UpdateFunc) ([]byte, error) {
+	var result []byte
+	var fnErr error
+	_, _, err := r.cache.Update(key, func(value []byte, found bool) ([]byte, bool, int) {
+		var header valueHeader
+		if found {
+			value, header = decodeValue(value)
+		} else {
+			value = nil
+		}
+		result, fnErr = fn(value)
+		return encodeValue(nil, result, header), fnErr == nil, expireIn
+	})
+	if fnErr != nil {
+		return nil, fnErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+const maxUpdateAttempts = 16
+
+// Update uses optimistic locking: transaction is retried if key changes between WATCH and EXEC
+func (r *rediscacherepo) Update(key []byte, expireIn int, fn UpdateFunc) ([]byte, error) {
+	conn, err := r.acquire()
+	if err != nil {
+		return nil, err
+	}
+	result, err := r.update(conn, key, expireIn, fn)
+	if err != nil && !errors.Is(err, ErrUpdateConflict) {
+		// connection may be left with watched keys or open transaction
+		conn.Close()
+		return nil, err
+	}
+	r.release(conn)
+	return result, err
+}
+func (r *rediscacherepo) update(conn *redisConn, key []byte, expireIn int, fn UpdateFunc) ([]byte, error) {
+	set := [][]byte{[]byte("SET"), key, nil}
+	if expireIn > 0 {
+		set = append(set, []byte("EX"), []byte(strconv.Itoa(expireIn)))
+	}
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(rand.Int64N(int64(attempt) * int64(time.Millisecond))))
+		}
+		if err := conn.expectOK([]byte("WATCH"), key); err != nil {
+			return nil, err
+		}
+		reply, err := conn.do([]byte("GET"), key)
+		if err != nil {
+			return nil, err
+		}
+		old, ok := reply.([]byte)
+		if !ok {
+			return nil, errors.New("unexpected GET reply")
+		}
+		value, err := fn(old)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			value = []byte{}
+		}
+		set[2] = value
+		if err := conn.expectOK([]byte("MULTI")); err != nil {
+			return nil, err
+		}
+		if err := conn.expectOK(set...); err != nil {
+			return nil, err
+		}
+		reply, err = conn.do([]byte("EXEC"))
+		if err != nil {
+			return nil, err
+		}
+		if e, ok := reply.(resp.Error); ok {
+			return nil, e
+		}
+		if results, ok := reply.([]any); ok && results != nil {
+			return value, nil
+		}
+	}
+	return nil, ErrUpdateConflict
+}
@@ -0,0 +1,240 @@
+package copier
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/copier"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"google.golang.org/protobuf/proto"
+)
+
+var ErrIdChanged = errors.New("document _id can't be updated")
+
+var (
+	marshalerType      = reflect.TypeFor[bson.Marshaler]()
+	valueMarshalerType = reflect.TypeFor[bsoncodec.ValueMarshaler]()
+	timeType           = reflect.TypeFor[time.Time]()
+)
+
+// UpdateDocument compares stored and updated documents and builds update with $set and $unset operators by bson field names.
+// Nested structs are compared field by field and updated by dotted paths, slices and maps are set as a whole.
+// Fields tagged omitempty which became empty are unset. Returns nil if documents are equal
+func UpdateDocument[T any](stored, updated *T) (bson.M, error) {
+	if stored == nil || updated == nil {
+		return nil, copier.ErrInvalidCopyFrom
+	}
+	from, to := reflect.ValueOf(stored).Elem(), reflect.ValueOf(updated).Elem()
+	if from.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unable to build update document for %T", stored)
+	}
+	set, unset := bson.M{}, bson.M{}
+	if err := diffDocument("", from, to, set, unset); err != nil {
+		return nil, err
+	}
+	update := bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	if len(update) == 0 {
+		return nil, nil
+	}
+	return update, nil
+}
+
+// PatchDocument applies patch onto stored document and returns update document for the changes, see UpdateDocument.
+// Proto messages are applied by Merge rules, other structs are copied with empty fields skipped, so nil pointers stay untouched.
+// Object ids are converted from strings the same way WithPrimitiveToStringConverter does. Stored document isn't changed on error
+func PatchDocument[T any](stored *T, patch any, options ...option) (bson.M, error) {
+	if stored == nil {
+		return nil, copier.ErrInvalidCopyDestination
+	}
+	var updated T
+	if err := copier.CopyWithOption(&updated, stored, copier.Option{DeepCopy: true}); err != nil {
+		return nil, err
+	}
+	options = append([]option{WithPrimitiveToStringConverter}, options...)
+	if message, ok := patch.(proto.Message); ok {
+		if _, err := Merge(&updated, message, options...); err != nil {
+			return nil, err
+		}
+	} else if err := Copy(&updated, patch, append(options, WithIgnoreEmptyFields)...); err != nil {
+		return nil, err
+	}
+	update, err := UpdateDocument(stored, &updated)
+	if err != nil {
+		return nil, err
+	}
+	keepNil(reflect.ValueOf(stored).Elem(), reflect.ValueOf(&updated).Elem())
+	*stored = updated
+	return update, nil
+}
+
+// keepNil restores nil slices and maps, which deep copy turns into empty ones, if they weren't filled by patch
+func keepNil(stored, updated reflect.Value) {
+	switch stored.Kind() {
+	case reflect.Slice, reflect.Map:
+		if stored.IsNil() && !updated.IsNil() && updated.Len() == 0 {
+			updated.SetZero()
+		}
+	case reflect.Pointer:
+		if !stored.IsNil() && !updated.IsNil() {
+			keepNil(stored.Elem(), updated.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < stored.NumField(); i++ {
+			if stored.Type().Field(i).IsExported() {
+				keepNil(stored.Field(i), updated.Field(i))
+			}
+		}
+	}
+}
+func diffDocument(prefix string, stored, updated reflect.Value, set, unset bson.M) error {
+	t := stored.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tags := parseBsonTags(field)
+		if tags.skip {
+			continue
+		}
+		from, to := stored.Field(i), updated.Field(i)
+		if tags.inline && from.Kind() == reflect.Struct {
+			if err := diffDocument(prefix, from, to, set, unset); err != nil {
+				return err
+			}
+			continue
+		}
+		if equalValues(from, to) {
+			continue
+		}
+		path := prefix + tags.name
+		if path == "_id" {
+			return ErrIdChanged
+		}
+		switch {
+		case tags.omitEmpty && to.IsZero():
+			unset[path] = ""
+		case subdocument(from, to):
+			if from.Kind() == reflect.Pointer {
+				from, to = from.Elem(), to.Elem()
+			}
+			if err := diffDocument(path+".", from, to, set, unset); err != nil {
+				return err
+			}
+		default:
+			set[path] = to.Interface()
+		}
+	}
+	return nil
+}
+
+// subdocument reports whether both values are structs encoded as embedded documents, so they can be updated field by field
+func subdocument(from, to reflect.Value) bool {
+	if from.Kind() == reflect.Pointer {
+		if from.IsNil() || to.IsNil() {
+			return false
+		}
+		from = from.Elem()
+	}
+	t := from.Type()
+	if t.Kind() != reflect.Struct || len(exportedFields(t)) == 0 {
+		return false
+	}
+	pointer := reflect.PointerTo(t)
+	return !pointer.Implements(marshalerType) && !pointer.Implements(valueMarshalerType)
+}
+
+// equalValues compares values as they're stored: times by instant, including ones behind pointers and in
+// slices, maps and structs, and nil and empty slices and maps as equal
+func equalValues(from, to reflect.Value) bool {
+	if from.Type() == timeType {
+		return from.Interface().(time.Time).Equal(to.Interface().(time.Time))
+	}
+	switch from.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if from.IsNil() || to.IsNil() {
+			return from.IsNil() == to.IsNil()
+		}
+		if from.Kind() == reflect.Interface && from.Elem().Type() != to.Elem().Type() {
+			return false
+		}
+		return equalValues(from.Elem(), to.Elem())
+	case reflect.Slice, reflect.Array:
+		if from.Len() != to.Len() {
+			return false
+		}
+		if from.Kind() == reflect.Slice && from.Type().Elem().Kind() == reflect.Uint8 {
+			return bytes.Equal(from.Bytes(), to.Bytes())
+		}
+		for i := 0; i < from.Len(); i++ {
+			if !equalValues(from.Index(i), to.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if from.Len() != to.Len() {
+			return false
+		}
+		iter := from.MapRange()
+		for iter.Next() {
+			value := to.MapIndex(iter.Key())
+			if !value.IsValid() || !equalValues(iter.Value(), value) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		if len(exportedFields(from.Type())) != from.NumField() {
+			return reflect.DeepEqual(from.Interface(), to.Interface())
+		}
+		for i := 0; i < from.NumField(); i++ {
+			if !equalValues(from.Field(i), to.Field(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(from.Interface(), to.Interface())
+}
+
+type bsonTags struct {
+	name      string
+	omitEmpty bool
+	inline    bool
+	skip      bool
+}
+
+// parseBsonTags reads field tags the same way bson struct codec does: name defaults to lowercased field name
+func parseBsonTags(field reflect.StructField) bsonTags {
+	tag, ok := field.Tag.Lookup("bson")
+	if !ok && !strings.Contains(string(field.Tag), ":") {
+		tag = string(field.Tag)
+	}
+	if tag == "-" {
+		return bsonTags{skip: true}
+	}
+	tags := bsonTags{name: strings.ToLower(field.Name)}
+	for i, part := range strings.Split(tag, ",") {
+		switch {
+		case i == 0 && part != "":
+			tags.name = part
+		case part == "omitempty":
+			tags.omitEmpty = true
+		case part == "inline":
+			tags.inline = true
+		}
+	}
+	return tags
+}
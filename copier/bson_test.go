@@ -0,0 +1,150 @@
+package copier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+type bsonGenre struct {
+	Name string `bson:"name"`
+	Slug string `bson:"slug,omitempty"`
+}
+type bsonAudit struct {
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+type bsonBook struct {
+	Id          primitive.ObjectID   `bson:"_id,omitempty"`
+	PublishedAt *time.Time           `bson:"published_at,omitempty"`
+	Editions    []time.Time          `bson:"editions"`
+	Title       string               `bson:"title"`
+	Authors     []primitive.ObjectID `bson:"authors"`
+	Description string               `bson:"description,omitempty"`
+	Rating      float64
+	Genre       *bsonGenre `bson:"genre,omitempty"`
+	Audit       bsonAudit  `bson:",inline"`
+	Cached      string     `bson:"-"`
+}
+
+func TestUpdateDocument(t *testing.T) {
+	id, author := primitive.NewObjectID(), primitive.NewObjectID()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	later := now.Add(time.Minute)
+	stored := bsonBook{Id: id, PublishedAt: &now, Editions: []time.Time{now}, Title: "book", Authors: []primitive.ObjectID{author}, Description: "about", Rating: 4.5, Genre: &bsonGenre{Name: "fantasy", Slug: "fantasy"}, Audit: bsonAudit{UpdatedAt: now}}
+
+	table := []struct {
+		Name     string
+		Update   func(*bsonBook)
+		Excepted bson.M
+	}{
+		{"equal", func(b *bsonBook) { b.Cached = "ignored" }, nil},
+		{"same instant", func(b *bsonBook) { b.Audit.UpdatedAt = now.In(time.FixedZone("MSK", 3*60*60)) }, nil},
+		{"same instant behind pointer and in slice", func(b *bsonBook) {
+			local := now.Local()
+			b.PublishedAt, b.Editions = &local, []time.Time{now.In(time.FixedZone("MSK", 3*60*60))}
+		}, nil},
+		{"changed instant behind pointer", func(b *bsonBook) { b.PublishedAt = &later }, bson.M{"$set": bson.M{"published_at": &later}}},
+		{"set and unset", func(b *bsonBook) { b.Title, b.Description, b.Rating = "new", "", 0 }, bson.M{
+			"$set":   bson.M{"title": "new", "rating": float64(0)},
+			"$unset": bson.M{"description": ""},
+		}},
+		{"nested field", func(b *bsonBook) { b.Genre.Name, b.Genre.Slug = "horror", "" }, bson.M{
+			"$set":   bson.M{"genre.name": "horror"},
+			"$unset": bson.M{"genre.slug": ""},
+		}},
+		{"removed subdocument", func(b *bsonBook) { b.Genre = nil }, bson.M{"$unset": bson.M{"genre": ""}}},
+		{"slice and inline field", func(b *bsonBook) {
+			b.Authors = append(b.Authors, id)
+			b.Audit.UpdatedAt = now.Add(time.Hour)
+		}, bson.M{"$set": bson.M{"authors": []primitive.ObjectID{author, id}, "updated_at": now.Add(time.Hour)}}},
+	}
+	for _, v := range table {
+		t.Run(v.Name, func(t *testing.T) {
+			var updated bsonBook
+			assert.NoError(t, Copy(&updated, &stored))
+			updated.Genre = &bsonGenre{Name: stored.Genre.Name, Slug: stored.Genre.Slug}
+			v.Update(&updated)
+
+			update, err := UpdateDocument(&stored, &updated)
+			assert.NoError(t, err)
+			assert.Equal(t, v.Excepted, update)
+		})
+	}
+
+	t.Run("new subdocument", func(t *testing.T) {
+		update, err := UpdateDocument(&bsonBook{Id: id}, &bsonBook{Id: id, Genre: &bsonGenre{Name: "fantasy"}})
+		assert.NoError(t, err)
+		assert.Equal(t, bson.M{"$set": bson.M{"genre": &bsonGenre{Name: "fantasy"}}}, update)
+	})
+	t.Run("changed id", func(t *testing.T) {
+		_, err := UpdateDocument(&bsonBook{Id: id}, &bsonBook{Id: author})
+		assert.ErrorIs(t, err, ErrIdChanged)
+	})
+}
+func TestPatchDocument(t *testing.T) {
+	type genrePatch struct {
+		Name *string
+	}
+	type patch struct {
+		Id          string
+		Title       *string
+		Authors     []string
+		Description *string
+		Genre       *genrePatch
+	}
+	id, author := primitive.NewObjectID(), primitive.NewObjectID()
+	newStored := func() bsonBook {
+		return bsonBook{Id: id, Title: "book", Description: "about", Genre: &bsonGenre{Name: "fantasy", Slug: "fantasy"}}
+	}
+
+	stored := newStored()
+	update, err := PatchDocument(&stored, &patch{Id: id.Hex(), Title: proto.String("new"), Authors: []string{author.Hex()}, Description: proto.String("")})
+	assert.NoError(t, err)
+	assert.Equal(t, bson.M{
+		"$set":   bson.M{"title": "new", "authors": []primitive.ObjectID{author}},
+		"$unset": bson.M{"description": ""},
+	}, update)
+	assert.Equal(t, bsonBook{Id: id, Title: "new", Authors: []primitive.ObjectID{author}, Genre: &bsonGenre{Name: "fantasy", Slug: "fantasy"}}, stored)
+
+	t.Run("nested patch", func(t *testing.T) {
+		stored := newStored()
+		update, err := PatchDocument(&stored, &patch{Genre: &genrePatch{Name: proto.String("horror")}})
+		assert.NoError(t, err)
+		assert.Equal(t, bson.M{"$set": bson.M{"genre.name": "horror"}}, update)
+		assert.Equal(t, &bsonGenre{Name: "horror", Slug: "fantasy"}, stored.Genre)
+	})
+	t.Run("nothing changed", func(t *testing.T) {
+		stored := newStored()
+		update, err := PatchDocument(&stored, &patch{Title: proto.String("book")})
+		assert.NoError(t, err)
+		assert.Nil(t, update)
+	})
+	t.Run("invalid id", func(t *testing.T) {
+		stored := newStored()
+		_, err := PatchDocument(&stored, &patch{Id: "not an id", Title: proto.String("new")})
+		assert.Error(t, err)
+		assert.Equal(t, newStored(), stored)
+	})
+	t.Run("changed id", func(t *testing.T) {
+		stored := newStored()
+		_, err := PatchDocument(&stored, &patch{Id: author.Hex()})
+		assert.ErrorIs(t, err, ErrIdChanged)
+		assert.Equal(t, newStored(), stored)
+	})
+	t.Run("proto patch", func(t *testing.T) {
+		type field struct {
+			Name   string `bson:"name"`
+			Number int32  `bson:"number,omitempty"`
+		}
+		stored := field{Name: "old", Number: 3}
+		update, err := PatchDocument(&stored, &descriptorpb.FieldDescriptorProto{Name: proto.String("title"), Number: proto.Int32(0)})
+		assert.NoError(t, err)
+		assert.Equal(t, bson.M{"$set": bson.M{"name": "title"}, "$unset": bson.M{"number": ""}}, update)
+		assert.Equal(t, field{Name: "title"}, stored)
+	})
+}
@@ -0,0 +1,52 @@
+package copier
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/jinzhu/copier"
+)
+
+var registry struct {
+	sync.RWMutex
+	converters []copier.TypeConverter
+}
+
+// WithConverter converts S into D with fn. Slices and pointers are converted the same way as WithProtoWellKnownTypes does
+func WithConverter[S, D any](fn func(S) (D, error)) option {
+	converters := typeConverters(fn)
+	return func(c *options) {
+		c.Converters = append(c.Converters, converters...)
+	}
+}
+
+// RegisterConverter adds converter used by every Copy, CopyTo, CopySlice and Mapper.
+// It's meant to be called once at startup. Converters passed with options take precedence over registered ones
+func RegisterConverter[S, D any](fn func(S) (D, error)) {
+	converters := typeConverters(fn)
+
+	registry.Lock()
+	defer registry.Unlock()
+	registry.converters = append(registry.converters, converters...)
+}
+
+// resetConverters drops every registered converter, so tests don't leak them into each other
+func resetConverters() {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.converters = nil
+}
+
+// registeredConverters returns converters which can be used while copying src type into dst type.
+// Copier converts whole struct at once if any converter is passed and types are convertible, so they are skipped in this case
+func registeredConverters(dstType, srcType reflect.Type) []copier.TypeConverter {
+	registry.RLock()
+	defer registry.RUnlock()
+	if len(registry.converters) == 0 {
+		return nil
+	}
+	if src, dst := structType(srcType), structType(dstType); src.Kind() == reflect.Struct && dst.Kind() == reflect.Struct && src.ConvertibleTo(dst) {
+		return nil
+	}
+	return append([]copier.TypeConverter(nil), reachableConverters(srcType, registry.converters)...)
+}
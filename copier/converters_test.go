@@ -0,0 +1,106 @@
+package copier
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type converterStatus int32
+
+const (
+	converterStatusDraft converterStatus = iota
+	converterStatusPublished
+)
+
+var converterStatusNames = []string{"DRAFT", "PUBLISHED"}
+
+type converterMoney struct {
+	Units int64
+	Cents int64
+}
+
+func TestWithConverter(t *testing.T) {
+	type model struct {
+		Title  string
+		Status string
+		Tags   []string
+	}
+	type message struct {
+		Title  string
+		Status converterStatus
+		Tags   []converterStatus
+	}
+	toStatus := WithConverter(func(s string) (converterStatus, error) {
+		for i, name := range converterStatusNames {
+			if name == s {
+				return converterStatus(i), nil
+			}
+		}
+		return 0, fmt.Errorf("unknown status %s", s)
+	})
+
+	var result message
+	assert.NoError(t, Copy(&result, &model{Title: "book", Status: "PUBLISHED", Tags: []string{"DRAFT", "PUBLISHED"}}, toStatus))
+	assert.Equal(t, message{Title: "book", Status: converterStatusPublished, Tags: []converterStatus{converterStatusDraft, converterStatusPublished}}, result)
+
+	err := Copy(&result, &model{Status: "DELETED"}, toStatus)
+	assert.EqualError(t, err, "unknown status DELETED")
+}
+func TestRegisteredConverter(t *testing.T) {
+	t.Cleanup(resetConverters)
+	RegisterConverter(func(s converterStatus) (string, error) {
+		if int(s) >= len(converterStatusNames) || s < 0 {
+			return "", fmt.Errorf("unknown status %d", s)
+		}
+		return converterStatusNames[s], nil
+	})
+	RegisterConverter(func(m converterMoney) (int64, error) { return m.Units*100 + m.Cents, nil })
+
+	type model struct {
+		Status converterStatus
+		Price  *converterMoney
+		Prices []converterMoney
+	}
+	type message struct {
+		Status string
+		Price  int64
+		Prices []int64
+	}
+	src := model{Status: converterStatusPublished, Price: &converterMoney{Units: 12, Cents: 50}, Prices: []converterMoney{{Units: 1}, {Cents: 99}}}
+	excepted := message{Status: "PUBLISHED", Price: 1250, Prices: []int64{100, 99}}
+
+	var result message
+	assert.NoError(t, Copy(&result, &src))
+	assert.Equal(t, excepted, result)
+
+	mapped, err := CopyTo[*message](&src)
+	assert.NoError(t, err)
+	assert.Equal(t, &excepted, mapped)
+
+	mapper, err := NewMapper[model, message]()
+	assert.NoError(t, err)
+	mapped, err = mapper.Map(&src)
+	assert.NoError(t, err)
+	assert.Equal(t, &excepted, mapped)
+
+	_, err = CopyTo[message](&model{Status: 10})
+	assert.EqualError(t, err, "unknown status 10")
+
+	t.Run("option takes precedence", func(t *testing.T) {
+		lower := WithConverter(func(s converterStatus) (string, error) {
+			return strings.ToLower(converterStatusNames[s]), nil
+		})
+		var result message
+		assert.NoError(t, Copy(&result, &src, lower))
+		assert.Equal(t, "published", result.Status)
+	})
+	t.Run("converter error", func(t *testing.T) {
+		failing := WithConverter(func(m converterMoney) (int64, error) { return 0, errors.New("conversion failed") })
+		var result message
+		assert.EqualError(t, Copy(&result, &src, failing), "conversion failed")
+	})
+}
@@ -1,10 +1,74 @@
 package copier
 
-import "github.com/jinzhu/copier"
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/jinzhu/copier"
+)
 
 func Copy(dst any, src any, options ...option) error {
-	if err := copier.CopyWithOption(dst, src, copyOption(options...)); err != nil {
+	o := copyOption(options...)
+	opt, err := o.resolve(reflect.TypeOf(dst), reflect.TypeOf(src))
+	if err != nil {
+		return err
+	}
+	if err := o.checkStrict(reflect.TypeOf(dst), reflect.ValueOf(src), opt); err != nil {
+		return err
+	}
+	if err := copier.CopyWithOption(dst, src, opt); err != nil {
 		return err
 	}
 	return nil
 }
+
+// CopyTo copies src into new value of type T. If T is a pointer, e.g. *books_pb.Book, it points to new allocated value
+func CopyTo[T any](src any, options ...option) (T, error) {
+	var dst T
+	o := copyOption(options...)
+	opt, err := o.resolve(reflect.TypeFor[T](), reflect.TypeOf(src))
+	if err != nil {
+		return dst, err
+	}
+	if err := o.checkStrict(reflect.TypeFor[T](), reflect.ValueOf(src), opt); err != nil {
+		return dst, err
+	}
+	if err := copyTo(&dst, src, opt); err != nil {
+		var zero T
+		return zero, err
+	}
+	return dst, nil
+}
+
+// CopySlice copies every element of src into new slice. Nil elements stay zero values
+func CopySlice[S, D any](src []S, options ...option) ([]D, error) {
+	if src == nil {
+		return nil, nil
+	}
+	o := copyOption(options...)
+	opt, err := o.resolve(reflect.TypeFor[D](), reflect.TypeFor[S]())
+	if err != nil {
+		return nil, err
+	}
+	if err := o.checkStrict(reflect.TypeFor[[]D](), reflect.ValueOf(src), opt); err != nil {
+		return nil, err
+	}
+	result := make([]D, len(src))
+	for i := range src {
+		if err := copyTo(&result[i], src[i], opt); err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+	return result, nil
+}
+func copyTo(dst any, src any, opt copier.Option) error {
+	if source := reflect.ValueOf(src); !source.IsValid() || (source.Kind() == reflect.Pointer && source.IsNil()) {
+		return nil
+	}
+	target := reflect.ValueOf(dst).Elem()
+	if target.Kind() == reflect.Pointer {
+		target.Set(reflect.New(target.Type().Elem()))
+		dst = target.Interface()
+	}
+	return copier.CopyWithOption(dst, src, opt)
+}
@@ -3,6 +3,7 @@ package copier
 import (
 	"testing"
 
+	"github.com/jinzhu/copier"
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -65,6 +66,19 @@ func TestIgnoreEmpty(t *testing.T) {
 	assert.Equal(t, "256256", new.Empty)
 	assert.Empty(t, base.Empty)
 }
+func TestCopierOption(t *testing.T) {
+	type str struct {
+		Str   string
+		Empty string
+	}
+	new := str{Empty: "256256"}
+	err := Copy(&new, &str{Str: "hello string"}, WithCopierOption(func(o *copier.Option) {
+		o.IgnoreEmpty = true
+	}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, str{Str: "hello string", Empty: "256256"}, new)
+}
 func TestPrimitiveToString(t *testing.T) {
 	str := struct {
 		Id    primitive.ObjectID
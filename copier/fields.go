@@ -0,0 +1,255 @@
+package copier
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jinzhu/copier"
+)
+
+// ignoredFieldPrefix prefixes mapping target of ignored fields. Such name never matches any field, so copier skips them
+const ignoredFieldPrefix = "-"
+
+type fieldMapping struct {
+	src, dst string
+}
+
+// WithFieldMapping copies src field into dst field, e.g. WithFieldMapping("AuthorIds", "Authors").
+// Nested fields are set by dotted paths of the same depth: WithFieldMapping("Genre.Name", "Genre.Title").
+// Copy fails if any of the fields doesn't exist. Since copier maps fields by types, nested path fails
+// as well if its structs are copied at several paths, e.g. Genre and []Genre fields of the same types
+func WithFieldMapping(src, dst string) option {
+	return func(c *options) {
+		c.fieldMappings = append(c.fieldMappings, fieldMapping{src: src, dst: dst})
+	}
+}
+
+// WithIgnoreFields leaves destination fields untouched. Nested fields are set by dotted paths, e.g. "Genre.Name".
+// Copy fails if any of the fields doesn't exist in destination or nested path is ambiguous, see WithFieldMapping
+func WithIgnoreFields(fields ...string) option {
+	return func(c *options) {
+		c.ignoreFields = append(c.ignoreFields, fields...)
+	}
+}
+
+// WithCaseInsensitive makes field paths of other options match regardless of case. It also matches fields
+// which names differ only in underscores, e.g. Author_Id and AuthorId. Fields differing only in case
+// are matched by copier anyway
+func WithCaseInsensitive() option {
+	return func(c *options) {
+		c.caseInsensitive = true
+	}
+}
+
+type typePair struct {
+	src, dst reflect.Type
+}
+
+// resolve builds copier options for copying src type into dst type, converting field rules to name mappings
+func (o *options) resolve(dstType, srcType reflect.Type) (copier.Option, error) {
+	opt := o.Option
+	if dstType == nil || srcType == nil {
+		return opt, nil
+	}
+	if registered := registeredConverters(dstType, srcType); len(registered) > 0 {
+		opt.Converters = append(registered, opt.Converters...)
+	}
+	if len(o.fieldMappings) == 0 && len(o.ignoreFields) == 0 && !o.caseInsensitive {
+		return opt, nil
+	}
+	dstType, srcType = structType(dstType), structType(srcType)
+	if dstType.Kind() != reflect.Struct || srcType.Kind() != reflect.Struct {
+		return opt, nil
+	}
+	root := typePair{src: srcType, dst: dstType}
+	mappings := make(map[typePair]map[string]string)
+	add := func(pair typePair, src, dst string) {
+		if mappings[pair] == nil {
+			mappings[pair] = make(map[string]string)
+		}
+		mappings[pair][src] = dst
+	}
+	// rules of nested paths, which must be the only paths their pairs are copied at
+	type nestedRule struct {
+		pair typePair
+		rule string
+	}
+	var nested []nestedRule
+
+	for _, m := range o.fieldMappings {
+		srcPath, dstPath := strings.Split(m.src, "."), strings.Split(m.dst, ".")
+		if len(srcPath) != len(dstPath) {
+			return opt, fmt.Errorf("field mapping %s -> %s: paths must have the same depth", m.src, m.dst)
+		}
+		pair := root
+		for i := range srcPath {
+			srcField, ok := o.field(pair.src, srcPath[i])
+			if !ok {
+				return opt, fmt.Errorf("field mapping %s -> %s: source field %s not found in %v", m.src, m.dst, srcPath[i], pair.src)
+			}
+			dstField, ok := o.field(pair.dst, dstPath[i])
+			if !ok {
+				return opt, fmt.Errorf("field mapping %s -> %s: destination field %s not found in %v", m.src, m.dst, dstPath[i], pair.dst)
+			}
+			if i == len(srcPath)-1 {
+				if mapped, ok := mappings[pair][srcField.Name]; ok && mapped != dstField.Name {
+					return opt, fmt.Errorf("field mapping %s -> %s: source field %s is already mapped to %s", m.src, m.dst, srcField.Name, mapped)
+				}
+				add(pair, srcField.Name, dstField.Name)
+				if i > 0 {
+					nested = append(nested, nestedRule{pair: pair, rule: fmt.Sprintf("field mapping %s -> %s", m.src, m.dst)})
+				}
+				break
+			}
+			pair = typePair{src: structType(srcField.Type), dst: structType(dstField.Type)}
+		}
+	}
+
+	if o.caseInsensitive {
+		o.looseMappings(root, mappings, make(map[typePair]bool))
+	}
+
+	for _, path := range o.ignoreFields {
+		segments := strings.Split(path, ".")
+		pair := root
+		for i, segment := range segments {
+			dstField, ok := o.field(pair.dst, segment)
+			if !ok {
+				return opt, fmt.Errorf("ignored field %s: field %s not found in %v", path, segment, pair.dst)
+			}
+			if i == len(segments)-1 {
+				// keeps ignored field known even if no source field is copied into it
+				add(pair, ignoredFieldPrefix+dstField.Name, ignoredFieldPrefix+dstField.Name)
+				for _, srcField := range exportedFields(pair.src) {
+					if target := targetName(srcField.Name, mappings[pair]); strings.EqualFold(target, dstField.Name) {
+						add(pair, srcField.Name, ignoredFieldPrefix+dstField.Name)
+					}
+				}
+				if i > 0 {
+					nested = append(nested, nestedRule{pair: pair, rule: "ignored field " + path})
+				}
+				break
+			}
+			srcField, ok := sourceFor(pair.src, dstField.Name, mappings[pair])
+			if !ok {
+				// nothing is copied into this field, so there is nothing to ignore
+				break
+			}
+			pair = typePair{src: structType(srcField.Type), dst: structType(dstField.Type)}
+		}
+	}
+
+	if len(nested) > 0 {
+		paths := pairPaths(root, mappings)
+		for _, n := range nested {
+			if paths[n.pair] > 1 {
+				return opt, fmt.Errorf("%s: %v is copied into %v at several paths, so the rule would apply to all of them", n.rule, n.pair.src, n.pair.dst)
+			}
+		}
+	}
+	for pair, mapping := range mappings {
+		opt.FieldNameMapping = append(opt.FieldNameMapping, copier.FieldNameMapping{
+			SrcType: reflect.New(pair.src).Elem().Interface(),
+			DstType: reflect.New(pair.dst).Elem().Interface(),
+			Mapping: mapping,
+		})
+	}
+	return opt, nil
+}
+
+// looseMappings maps fields which names differ only in case and underscores in pair and nested struct pairs
+func (o *options) looseMappings(pair typePair, mappings map[typePair]map[string]string, visited map[typePair]bool) {
+	if visited[pair] || pair.src.Kind() != reflect.Struct || pair.dst.Kind() != reflect.Struct {
+		return
+	}
+	visited[pair] = true
+	for _, srcField := range exportedFields(pair.src) {
+		target := targetName(srcField.Name, mappings[pair])
+		dstField, ok := pair.dst.FieldByNameFunc(func(n string) bool { return strings.EqualFold(n, target) })
+		if !ok {
+			for _, candidate := range exportedFields(pair.dst) {
+				if looseName(candidate.Name) == looseName(target) {
+					if mappings[pair] == nil {
+						mappings[pair] = make(map[string]string)
+					}
+					mappings[pair][srcField.Name] = candidate.Name
+					dstField, ok = candidate, true
+					break
+				}
+			}
+		}
+		if ok {
+			o.looseMappings(typePair{src: structType(srcField.Type), dst: structType(dstField.Type)}, mappings, visited)
+		}
+	}
+}
+
+// pairPaths counts paths by which struct pairs are reached from root following fields copied into each other.
+// Pair is walked through at most twice: it's enough to tell that nothing below it is unique, and it stops recursion
+func pairPaths(root typePair, mappings map[typePair]map[string]string) map[typePair]int {
+	paths := make(map[typePair]int)
+	var walk func(pair typePair)
+	walk = func(pair typePair) {
+		paths[pair]++
+		if paths[pair] > 2 || pair.src.Kind() != reflect.Struct || pair.dst.Kind() != reflect.Struct {
+			return
+		}
+		for _, srcField := range exportedFields(pair.src) {
+			target := targetName(srcField.Name, mappings[pair])
+			if dstField, ok := pair.dst.FieldByNameFunc(func(n string) bool { return strings.EqualFold(n, target) }); ok {
+				walk(typePair{src: structType(srcField.Type), dst: structType(dstField.Type)})
+			}
+		}
+	}
+	walk(root)
+	return paths
+}
+func (o *options) field(t reflect.Type, name string) (reflect.StructField, bool) {
+	if t.Kind() != reflect.Struct {
+		return reflect.StructField{}, false
+	}
+	if !o.caseInsensitive {
+		return t.FieldByName(name)
+	}
+	return t.FieldByNameFunc(func(n string) bool { return looseName(n) == looseName(name) })
+}
+
+// sourceFor finds source field copied into destination field
+func sourceFor(src reflect.Type, dst string, mapping map[string]string) (reflect.StructField, bool) {
+	for _, f := range exportedFields(src) {
+		if strings.EqualFold(targetName(f.Name, mapping), dst) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+func targetName(name string, mapping map[string]string) string {
+	if target, ok := mapping[name]; ok {
+		return target
+	}
+	return name
+}
+func exportedFields(t reflect.Type) []reflect.StructField {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	fields := make([]reflect.StructField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.IsExported() {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+func looseName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// structType strips pointers and slices the same way copier does
+func structType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t
+}
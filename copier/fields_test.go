@@ -0,0 +1,157 @@
+package copier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type fieldsGenre struct {
+	Name string
+	Slug string
+}
+type fieldsModel struct {
+	Id        primitive.ObjectID
+	Title     string
+	AuthorIds []primitive.ObjectID
+	Author_Id primitive.ObjectID
+	Rating    float64
+	Genre     *fieldsGenre
+}
+type fieldsGenreMessage struct {
+	Title string
+	Slug  string
+}
+type fieldsMessage struct {
+	Id       string
+	Name     string
+	Authors  []string
+	AuthorId string
+	Rating   float64
+	Genre    *fieldsGenreMessage
+}
+
+type fieldsCollection struct {
+	Main   *fieldsGenre
+	Others []fieldsGenre
+}
+type fieldsCollectionMessage struct {
+	Main   *fieldsGenreMessage
+	Others []fieldsGenreMessage
+}
+type fieldsCategory struct {
+	Name   string
+	Genre  fieldsGenre
+	Parent *fieldsCategory
+}
+type fieldsCategoryMessage struct {
+	Name   string
+	Genre  fieldsGenreMessage
+	Parent *fieldsCategoryMessage
+}
+
+func TestFieldMapping(t *testing.T) {
+	id, author := primitive.NewObjectID(), primitive.NewObjectID()
+	model := fieldsModel{
+		Id:        id,
+		Title:     "book",
+		AuthorIds: []primitive.ObjectID{author},
+		Author_Id: author,
+		Rating:    4.5,
+		Genre:     &fieldsGenre{Name: "fantasy", Slug: "fantasy-slug"},
+	}
+
+	table := []struct {
+		Name     string
+		Options  []option
+		Excepted fieldsMessage
+	}{
+		{"no mapping", nil, fieldsMessage{Id: id.Hex(), Rating: 4.5, Genre: &fieldsGenreMessage{Slug: "fantasy-slug"}}},
+		{"renamed fields", []option{WithFieldMapping("Title", "Name"), WithFieldMapping("AuthorIds", "Authors")},
+			fieldsMessage{Id: id.Hex(), Name: "book", Authors: []string{author.Hex()}, Rating: 4.5, Genre: &fieldsGenreMessage{Slug: "fantasy-slug"}}},
+		{"nested field", []option{WithFieldMapping("Genre.Name", "Genre.Title")},
+			fieldsMessage{Id: id.Hex(), Rating: 4.5, Genre: &fieldsGenreMessage{Title: "fantasy", Slug: "fantasy-slug"}}},
+		{"ignored fields", []option{WithFieldMapping("Title", "Name"), WithIgnoreFields("Name", "Genre.Slug", "Rating")},
+			fieldsMessage{Id: id.Hex(), Genre: &fieldsGenreMessage{}}},
+		{"case insensitive", []option{WithCaseInsensitive(), WithFieldMapping("title", "name"), WithFieldMapping("genre.name", "genre.title")},
+			fieldsMessage{Id: id.Hex(), Name: "book", AuthorId: author.Hex(), Rating: 4.5, Genre: &fieldsGenreMessage{Title: "fantasy", Slug: "fantasy-slug"}}},
+	}
+	for _, v := range table {
+		t.Run(v.Name, func(t *testing.T) {
+			options := append([]option{WithPrimitiveToStringConverter}, v.Options...)
+
+			var message fieldsMessage
+			assert.NoError(t, Copy(&message, &model, options...))
+			assert.Equal(t, v.Excepted, message)
+
+			got, err := CopyTo[*fieldsMessage](&model, options...)
+			assert.NoError(t, err)
+			assert.Equal(t, &v.Excepted, got)
+
+			mapper, err := NewMapper[fieldsModel, fieldsMessage](options...)
+			assert.NoError(t, err)
+			mapped, err := mapper.Map(&model)
+			assert.NoError(t, err)
+			assert.Equal(t, &v.Excepted, mapped)
+		})
+	}
+}
+func TestFieldMappingErrors(t *testing.T) {
+	table := []struct {
+		Name    string
+		Options []option
+	}{
+		{"missing source field", []option{WithFieldMapping("Authors", "Authors")}},
+		{"missing destination field", []option{WithFieldMapping("Title", "Title")}},
+		{"missing nested field", []option{WithFieldMapping("Genre.Title", "Genre.Title")}},
+		{"different depth", []option{WithFieldMapping("Genre.Name", "Name")}},
+		{"conflicting mappings", []option{WithFieldMapping("Title", "Name"), WithFieldMapping("Title", "AuthorId")}},
+		{"case sensitive", []option{WithFieldMapping("title", "name")}},
+		{"missing ignored field", []option{WithIgnoreFields("Title")}},
+		{"missing nested ignored field", []option{WithIgnoreFields("Genre.Name")}},
+	}
+	for _, v := range table {
+		t.Run(v.Name, func(t *testing.T) {
+			var message fieldsMessage
+			assert.Error(t, Copy(&message, &fieldsModel{}, v.Options...))
+
+			_, err := CopySlice[fieldsModel, fieldsMessage]([]fieldsModel{{}}, v.Options...)
+			assert.Error(t, err)
+
+			_, err = NewMapper[fieldsModel, fieldsMessage](v.Options...)
+			assert.Error(t, err)
+		})
+	}
+}
+func TestAmbiguousNestedFields(t *testing.T) {
+	table := []struct {
+		Name    string
+		Copy    func(options ...option) error
+		Options []option
+		Error   bool
+	}{
+		{"same types at several fields", copyCollection, []option{WithFieldMapping("Main.Name", "Main.Title")}, true},
+		{"ignored field of same types at several fields", copyCollection, []option{WithIgnoreFields("Main.Slug")}, true},
+		{"nested field of recursive type", copyCategory, []option{WithFieldMapping("Genre.Name", "Genre.Title")}, true},
+		{"top level field of recursive type", copyCategory, []option{WithIgnoreFields("Name")}, false},
+	}
+	for _, v := range table {
+		t.Run(v.Name, func(t *testing.T) {
+			err := v.Copy(v.Options...)
+			if v.Error {
+				assert.ErrorContains(t, err, "several paths")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+func copyCollection(options ...option) error {
+	var message fieldsCollectionMessage
+	return Copy(&message, &fieldsCollection{Main: &fieldsGenre{Name: "fantasy"}, Others: []fieldsGenre{{Name: "horror"}}}, options...)
+}
+func copyCategory(options ...option) error {
+	var message fieldsCategoryMessage
+	return Copy(&message, &fieldsCategory{Name: "child", Parent: &fieldsCategory{Name: "parent"}}, options...)
+}
@@ -0,0 +1,62 @@
+package copier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type genericModel struct {
+	Id   primitive.ObjectID
+	Name string
+}
+type genericMessage struct {
+	Id   string
+	Name string
+}
+
+func TestCopyTo(t *testing.T) {
+	model := genericModel{Id: primitive.NewObjectID(), Name: "book"}
+
+	message, err := CopyTo[genericMessage](&model, WithPrimitiveToStringConverter)
+	assert.NoError(t, err)
+	assert.Equal(t, genericMessage{Id: model.Id.Hex(), Name: "book"}, message)
+
+	pointer, err := CopyTo[*genericMessage](model, WithPrimitiveToStringConverter)
+	assert.NoError(t, err)
+	if assert.NotNil(t, pointer) {
+		assert.Equal(t, message, *pointer)
+	}
+
+	empty, err := CopyTo[*genericMessage]((*genericModel)(nil))
+	assert.NoError(t, err)
+	assert.Nil(t, empty)
+
+	_, err = CopyTo[genericModel](genericMessage{Id: "wrong id"}, WithPrimitiveToStringConverter)
+	assert.Error(t, err)
+}
+func TestCopySlice(t *testing.T) {
+	models := []*genericModel{
+		{Id: primitive.NewObjectID(), Name: "first"},
+		nil,
+		{Id: primitive.NewObjectID(), Name: "third"},
+	}
+	messages, err := CopySlice[*genericModel, *genericMessage](models, WithPrimitiveToStringConverter)
+	assert.NoError(t, err)
+	if assert.Len(t, messages, 3) {
+		assert.Equal(t, &genericMessage{Id: models[0].Id.Hex(), Name: "first"}, messages[0])
+		assert.Nil(t, messages[1])
+		assert.Equal(t, "third", messages[2].Name)
+	}
+
+	nilSlice, err := CopySlice[genericModel, genericMessage](nil)
+	assert.NoError(t, err)
+	assert.Nil(t, nilSlice)
+
+	_, err = CopySlice[genericMessage, genericModel]([]genericMessage{
+		{Id: primitive.NewObjectID().Hex()},
+		{Id: "wrong id"},
+	}, WithPrimitiveToStringConverter)
+	assert.ErrorContains(t, err, "element 1:")
+}
@@ -0,0 +1,264 @@
+package copier
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jinzhu/copier"
+)
+
+// Mapper copies S structs into D structs the same way Copy does, but resolves fields and converters once.
+// Fields which need recursive copying (pointers, nested structs of different types, etc.) are still copied by reflection walk
+type Mapper[S, D any] struct {
+	opt        copier.Option
+	ops        []fieldOp
+	unexported bool // S is assignable to D, so unexported fields are copied too
+	fallback   bool // types use features which plan doesn't support, so every copy is delegated to Copy
+	options    *options
+}
+
+type fieldOp struct {
+	src, dst int
+	convert  func(any) (any, error)
+	assign   bool
+	dstType  reflect.Type
+
+	// single-field wrapper types to copy field with all nested rules
+	srcWrapper, dstWrapper reflect.Type
+	wrapperOpt             copier.Option
+}
+
+func NewMapper[S, D any](options ...option) (*Mapper[S, D], error) {
+	srcType, dstType := reflect.TypeFor[S](), reflect.TypeFor[D]()
+	if srcType.Kind() != reflect.Struct || dstType.Kind() != reflect.Struct {
+		return nil, errors.New("mapper can be created only for struct types")
+	}
+	o := copyOption(options...)
+	opt, err := o.resolve(dstType, srcType)
+	if err != nil {
+		return nil, err
+	}
+	m := &Mapper[S, D]{opt: opt, options: o}
+	m.fallback = !planSupported(srcType, dstType, m.opt)
+	if m.fallback {
+		return m, nil
+	}
+	m.unexported = srcType.AssignableTo(dstType)
+
+	var names map[string]string
+	for _, mapping := range m.opt.FieldNameMapping {
+		if reflect.TypeOf(mapping.SrcType) == srcType && reflect.TypeOf(mapping.DstType) == dstType {
+			names = mapping.Mapping
+		}
+	}
+	converters := make(map[[2]reflect.Type]copier.TypeConverter)
+	for _, c := range m.opt.Converters {
+		converters[[2]reflect.Type{reflect.TypeOf(c.SrcType), reflect.TypeOf(c.DstType)}] = c
+	}
+	for i := 0; i < srcType.NumField(); i++ {
+		from := srcType.Field(i)
+		if !from.IsExported() {
+			continue
+		}
+		to, ok := destinationField(dstType, targetName(from.Name, names), m.opt.CaseSensitive)
+		if !ok || !to.IsExported() {
+			continue
+		}
+		op := fieldOp{src: i, dst: to.Index[0], dstType: to.Type}
+		if c, ok := converters[[2]reflect.Type{from.Type, to.Type}]; ok {
+			op.convert = c.Fn
+		} else if !m.opt.DeepCopy && from.Type.Kind() != reflect.Pointer && to.Type.Kind() != reflect.Pointer && from.Type.ConvertibleTo(to.Type) {
+			op.assign = true
+		} else {
+			op.srcWrapper = reflect.StructOf([]reflect.StructField{{Name: "Src", Type: from.Type}})
+			op.dstWrapper = reflect.StructOf([]reflect.StructField{{Name: "Dst", Type: to.Type}})
+			op.wrapperOpt = m.opt
+			op.wrapperOpt.Converters = reachableConverters(from.Type, m.opt.Converters)
+			op.wrapperOpt.FieldNameMapping = append(append([]copier.FieldNameMapping{}, m.opt.FieldNameMapping...), copier.FieldNameMapping{
+				SrcType: reflect.New(op.srcWrapper).Elem().Interface(),
+				DstType: reflect.New(op.dstWrapper).Elem().Interface(),
+				Mapping: map[string]string{"Src": "Dst"},
+			})
+		}
+		m.ops = append(m.ops, op)
+	}
+	return m, nil
+}
+func destinationField(dstType reflect.Type, name string, caseSensitive bool) (reflect.StructField, bool) {
+	if caseSensitive {
+		return dstType.FieldByName(name)
+	}
+	return dstType.FieldByNameFunc(func(n string) bool { return strings.EqualFold(n, name) })
+}
+
+// planSupported reports whether types can be copied field by field without embedded structs, copier tags,
+// whole-type converters and copying through methods
+func planSupported(srcType, dstType reflect.Type, opt copier.Option) bool {
+	// copier converts whole convertible struct at once when any converter is registered
+	if len(opt.Converters) > 0 && srcType.ConvertibleTo(dstType) {
+		return false
+	}
+	for _, c := range opt.Converters {
+		if reflect.TypeOf(c.SrcType) == srcType && reflect.TypeOf(c.DstType) == dstType {
+			return false
+		}
+	}
+	for _, t := range []reflect.Type{srcType, dstType} {
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.Anonymous || f.Tag.Get("copier") != "" {
+				return false
+			}
+		}
+	}
+	srcPointer, dstPointer := reflect.PointerTo(srcType), reflect.PointerTo(dstType)
+	for i := 0; i < dstType.NumField(); i++ {
+		if method, ok := srcPointer.MethodByName(dstType.Field(i).Name); ok && method.Type.NumIn() == 1 && method.Type.NumOut() == 1 {
+			return false
+		}
+	}
+	for i := 0; i < srcType.NumField(); i++ {
+		name := srcType.Field(i).Name
+		if _, ok := dstType.FieldByNameFunc(func(n string) bool { return strings.EqualFold(n, name) }); ok {
+			continue
+		}
+		if method, ok := dstPointer.MethodByName(name); ok && method.Type.NumIn() == 2 {
+			return false
+		}
+	}
+	return true
+}
+
+// Copy copies src into dst. Result is the same as Copy(dst, src, options...) with options passed to NewMapper
+func (m *Mapper[S, D]) Copy(dst *D, src *S) error {
+	if dst == nil {
+		return copier.ErrInvalidCopyDestination
+	}
+	if src == nil {
+		return copier.ErrInvalidCopyFrom
+	}
+	if err := m.options.checkStrict(reflect.TypeFor[D](), reflect.ValueOf(src), m.opt); err != nil {
+		return err
+	}
+	if m.fallback {
+		return copier.CopyWithOption(dst, src, m.opt)
+	}
+	from, to := reflect.ValueOf(src).Elem(), reflect.ValueOf(dst).Elem()
+	if m.unexported {
+		copyUnexportedFields(to, from)
+	}
+	for i := range m.ops {
+		op := &m.ops[i]
+		source := from.Field(op.src)
+		if m.opt.IgnoreEmpty && source.IsZero() {
+			continue
+		}
+		target := to.Field(op.dst)
+		switch {
+		case op.convert != nil:
+			result, err := op.convert(source.Interface())
+			if err != nil {
+				return err
+			}
+			if result != nil {
+				target.Set(reflect.ValueOf(result))
+			} else {
+				target.Set(reflect.Zero(op.dstType))
+			}
+		case op.assign:
+			target.Set(source.Convert(op.dstType))
+		default:
+			srcWrapper := reflect.New(op.srcWrapper)
+			srcWrapper.Elem().Field(0).Set(source)
+			dstWrapper := reflect.New(op.dstWrapper)
+			dstWrapper.Elem().Field(0).Set(target)
+			if err := copier.CopyWithOption(dstWrapper.Interface(), srcWrapper.Interface(), op.wrapperOpt); err != nil {
+				return err
+			}
+			target.Set(dstWrapper.Elem().Field(0))
+		}
+	}
+	return nil
+}
+
+// Map copies src into new D. Nil src gives nil result
+func (m *Mapper[S, D]) Map(src *S) (*D, error) {
+	if src == nil {
+		return nil, nil
+	}
+	dst := new(D)
+	if err := m.Copy(dst, src); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+func (m *Mapper[S, D]) MapSlice(src []*S) ([]*D, error) {
+	if src == nil {
+		return nil, nil
+	}
+	result := make([]*D, len(src))
+	for i, s := range src {
+		d, err := m.Map(s)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		result[i] = d
+	}
+	return result, nil
+}
+
+// reachableConverters keeps only converters which source type can be met while copying value of type t,
+// since copier rebuilds converters lookup table on every nested call
+func reachableConverters(t reflect.Type, converters []copier.TypeConverter) []copier.TypeConverter {
+	reachable := make(map[reflect.Type]bool)
+	if !collectTypes(t, reachable) {
+		return converters
+	}
+	var result []copier.TypeConverter
+	for _, c := range converters {
+		if reachable[reflect.TypeOf(c.SrcType)] {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// collectTypes walks through exported fields only, as copier does. Returns false if interface type is met,
+// so any type is reachable
+func collectTypes(t reflect.Type, reachable map[reflect.Type]bool) bool {
+	if reachable[t] {
+		return true
+	}
+	reachable[t] = true
+	switch t.Kind() {
+	case reflect.Interface:
+		return false
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return collectTypes(t.Elem(), reachable)
+	case reflect.Map:
+		return collectTypes(t.Key(), reachable) && collectTypes(t.Elem(), reachable)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			if !collectTypes(t.Field(i).Type, reachable) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// copyUnexportedFields mirrors copier's behaviour for assignable structs
+func copyUnexportedFields(to, from reflect.Value) {
+	tmp := reflect.New(to.Type()).Elem()
+	tmp.Set(from)
+	for i := 0; i < to.NumField(); i++ {
+		if tmp.Field(i).CanSet() {
+			tmp.Field(i).Set(to.Field(i))
+		}
+	}
+	to.Set(tmp)
+}
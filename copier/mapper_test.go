@@ -0,0 +1,191 @@
+package copier
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type mapperAuthor struct {
+	Id   primitive.ObjectID
+	Name string
+}
+type mapperBook struct {
+	Id          primitive.ObjectID
+	Name        string
+	TranslitURL string
+	AuthorIds   []primitive.ObjectID
+	Authors     []*mapperAuthor
+	Genre       *mapperAuthor
+	Rating      float32
+	Pages       int
+	Created     time.Time
+	Tags        map[string]int
+	Hidden      bool
+	internal    string
+}
+type mapperAuthorMessage struct {
+	Id   string
+	Name string
+}
+type mapperBookMessage struct {
+	Id          string
+	Name        string
+	Transliturl string
+	AuthorIds   []string
+	Authors     []*mapperAuthorMessage
+	Genre       *mapperAuthorMessage
+	Rating      float64
+	Pages       int64
+	Created     *timestamppb.Timestamp
+	Tags        map[string]int
+	Missing     string
+	state       int
+}
+
+func mapperFixture() *mapperBook {
+	return &mapperBook{
+		Id:          primitive.NewObjectID(),
+		Name:        "book",
+		TranslitURL: "book-1",
+		AuthorIds:   []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID()},
+		Authors:     []*mapperAuthor{{Id: primitive.NewObjectID(), Name: "first"}, {Id: primitive.NewObjectID(), Name: "second"}},
+		Genre:       &mapperAuthor{Id: primitive.NewObjectID(), Name: "genre"},
+		Rating:      4.5,
+		Pages:       320,
+		Created:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Tags:        map[string]int{"new": 1},
+		internal:    "internal",
+	}
+}
+func TestMapperMatchesCopy(t *testing.T) {
+	table := []struct {
+		Name    string
+		Options []option
+		Dst     func() *mapperBookMessage
+	}{
+		{
+			Name:    "regular copy",
+			Options: []option{WithPrimitiveToStringConverter, WithProtoWellKnownTypes},
+			Dst:     func() *mapperBookMessage { return &mapperBookMessage{} },
+		},
+		{
+			Name:    "ignore empty fields",
+			Options: []option{WithPrimitiveToStringConverter, WithProtoWellKnownTypes, WithIgnoreEmptyFields},
+			Dst: func() *mapperBookMessage {
+				return &mapperBookMessage{Missing: "kept", Genre: &mapperAuthorMessage{Name: "old"}}
+			},
+		},
+		{
+			Name: "without converters",
+			Dst:  func() *mapperBookMessage { return &mapperBookMessage{} },
+		},
+	}
+	for _, v := range table {
+		t.Run(v.Name, func(t *testing.T) {
+			src := mapperFixture()
+			src.Hidden = false
+
+			excepted := v.Dst()
+			exceptedErr := Copy(excepted, src, v.Options...)
+
+			mapper, err := NewMapper[mapperBook, mapperBookMessage](v.Options...)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.False(t, mapper.fallback)
+			got := v.Dst()
+			err = mapper.Copy(got, src)
+
+			assert.Equal(t, exceptedErr, err)
+			assert.Equal(t, excepted, got)
+		})
+	}
+}
+func TestMapperSameType(t *testing.T) {
+	src := mapperFixture()
+
+	var excepted mapperBook
+	assert.NoError(t, Copy(&excepted, src))
+
+	mapper, err := NewMapper[mapperBook, mapperBook]()
+	assert.NoError(t, err)
+	var got mapperBook
+	assert.NoError(t, mapper.Copy(&got, src))
+	assert.Equal(t, excepted, got)
+	assert.Equal(t, "internal", got.internal)
+
+	mapper, err = NewMapper[mapperBook, mapperBook](WithIgnoreEmptyFields, WithPrimitiveToStringConverter)
+	assert.NoError(t, err)
+	assert.True(t, mapper.fallback)
+}
+func TestMapperFallback(t *testing.T) {
+	type tagged struct {
+		Name string `copier:"Title"`
+	}
+	type titled struct {
+		Title string
+	}
+	mapper, err := NewMapper[tagged, titled]()
+	assert.NoError(t, err)
+	assert.True(t, mapper.fallback)
+
+	got, err := mapper.Map(&tagged{Name: "book"})
+	assert.NoError(t, err)
+	assert.Equal(t, "book", got.Title)
+
+	_, err = NewMapper[*tagged, titled]()
+	assert.Error(t, err)
+}
+func TestMapperSlice(t *testing.T) {
+	mapper, err := NewMapper[mapperAuthorMessage, mapperAuthor](WithPrimitiveToStringConverter)
+	assert.NoError(t, err)
+
+	id := primitive.NewObjectID()
+	got, err := mapper.MapSlice([]*mapperAuthorMessage{{Id: id.Hex(), Name: "first"}, nil})
+	assert.NoError(t, err)
+	assert.Equal(t, []*mapperAuthor{{Id: id, Name: "first"}, nil}, got)
+
+	_, err = mapper.MapSlice([]*mapperAuthorMessage{{Id: id.Hex()}, {Id: "wrong"}})
+	assert.ErrorContains(t, err, "element 1:")
+
+	assert.Error(t, mapper.Copy(nil, &mapperAuthorMessage{}))
+	assert.Error(t, mapper.Copy(&mapperAuthor{}, nil))
+}
+
+func benchmarkBooks() []*mapperBook {
+	books := make([]*mapperBook, 1000)
+	for i := range books {
+		books[i] = mapperFixture()
+		books[i].Name = "book " + strconv.Itoa(i)
+	}
+	return books
+}
+func BenchmarkCopyBooks(b *testing.B) {
+	books := benchmarkBooks()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, book := range books {
+			var message mapperBookMessage
+			_ = Copy(&message, book, WithPrimitiveToStringConverter, WithProtoWellKnownTypes)
+		}
+	}
+}
+func BenchmarkMapperBooks(b *testing.B) {
+	books := benchmarkBooks()
+	mapper, err := NewMapper[mapperBook, mapperBookMessage](WithPrimitiveToStringConverter, WithProtoWellKnownTypes)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, book := range books {
+			var message mapperBookMessage
+			_ = mapper.Copy(&message, book)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package copier
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jinzhu/copier"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// WithFieldMask makes Merge apply only masked fields, zero values included. Empty mask is the same as no mask
+func WithFieldMask(mask *fieldmaskpb.FieldMask) option {
+	return func(c *options) {
+		c.fieldMask = mask
+	}
+}
+
+// Merge applies fields set in src onto dst struct and returns changed field paths in field mask notation.
+// Without WithFieldMask a field is applied if it's present in src: messages and fields with explicit presence
+// (optional, proto2) are applied even when they hold zero values, other fields only when they aren't empty.
+// Nested messages and maps are merged, lists are replaced. With WithFieldMask masked messages and maps are replaced,
+// while nested paths such as "options.deprecated" change only masked fields, even if src message isn't set
+func Merge(dst any, src proto.Message, options ...option) ([]string, error) {
+	target := reflect.ValueOf(dst)
+	if target.Kind() != reflect.Pointer || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return nil, copier.ErrInvalidCopyDestination
+	}
+	if src == nil || !src.ProtoReflect().IsValid() {
+		return nil, copier.ErrInvalidCopyFrom
+	}
+	o := copyOption(options...)
+	var mask fieldMaskTree
+	if paths := o.fieldMask.GetPaths(); len(paths) > 0 {
+		if !o.fieldMask.IsValid(src) {
+			return nil, fmt.Errorf("invalid field mask %v for %s", paths, src.ProtoReflect().Descriptor().FullName())
+		}
+		mask = newFieldMaskTree(paths)
+	}
+	opt, err := o.resolve(target.Type(), reflect.TypeOf(src))
+	if err != nil {
+		return nil, err
+	}
+	opt.DeepCopy, opt.IgnoreEmpty = true, false
+
+	m := merger{opt: opt, names: make(map[typePair]map[string]string), converters: make(map[typePair]bool)}
+	for _, mapping := range opt.FieldNameMapping {
+		m.names[typePair{src: reflect.TypeOf(mapping.SrcType), dst: reflect.TypeOf(mapping.DstType)}] = mapping.Mapping
+	}
+	for _, c := range opt.Converters {
+		m.converters[typePair{src: reflect.TypeOf(c.SrcType), dst: reflect.TypeOf(c.DstType)}] = true
+	}
+	return m.message(target.Elem(), src.ProtoReflect(), reflect.ValueOf(src).Elem(), mask, "")
+}
+
+var wellKnownFiles = map[string]bool{
+	"google/protobuf/any.proto":        true,
+	"google/protobuf/duration.proto":   true,
+	"google/protobuf/empty.proto":      true,
+	"google/protobuf/field_mask.proto": true,
+	"google/protobuf/struct.proto":     true,
+	"google/protobuf/timestamp.proto":  true,
+	"google/protobuf/wrappers.proto":   true,
+}
+
+type merger struct {
+	opt        copier.Option
+	names      map[typePair]map[string]string
+	converters map[typePair]bool
+}
+
+func (m *merger) message(dst reflect.Value, msg protoreflect.Message, value reflect.Value, mask fieldMaskTree, prefix string) ([]string, error) {
+	var changed []string
+	names := m.names[typePair{src: value.Type(), dst: dst.Type()}]
+	fields := msg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		sub, masked := mask[string(fd.Name())]
+		if mask != nil && !masked || mask == nil && !msg.Has(fd) {
+			continue
+		}
+		from, name, ok := goField(value, fd)
+		if !ok {
+			continue
+		}
+		field, ok := destinationField(dst.Type(), targetName(name, names), m.opt.CaseSensitive)
+		if !ok || !field.IsExported() {
+			continue
+		}
+		to, err := dst.FieldByIndexErr(field.Index)
+		if err != nil {
+			continue
+		}
+		path := prefix + string(fd.Name())
+
+		merge := mask == nil || sub != nil
+		switch {
+		case merge && m.nested(fd, from.Type(), to.Type()):
+			nestedMsg, nestedValue := msg.Get(fd).Message(), from
+			if from.IsNil() {
+				if sub == nil {
+					continue
+				}
+				// masked fields of unset message are cleared, the rest of destination is kept
+				nestedValue = reflect.New(from.Type().Elem())
+				nestedMsg = nestedValue.Interface().(proto.Message).ProtoReflect()
+			}
+			paths, err := m.nestedMessage(to, nestedMsg, nestedValue.Elem(), sub, path+".")
+			if err != nil {
+				return nil, err
+			}
+			changed = append(changed, paths...)
+		case merge && fd.IsMap() && to.Kind() == reflect.Map:
+			ok, err := m.mergeMap(to, from)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			if ok {
+				changed = append(changed, path)
+			}
+		default:
+			result, err := m.convert(from, to.Type())
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			if !reflect.DeepEqual(to.Interface(), result.Interface()) {
+				to.Set(result)
+				changed = append(changed, path)
+			}
+		}
+	}
+	return changed, nil
+}
+
+// nested reports whether message field is merged into struct field instead of being replaced.
+// Well-known types and fields with converters are treated as plain values
+func (m *merger) nested(fd protoreflect.FieldDescriptor, from, to reflect.Type) bool {
+	if fd.Message() == nil || fd.IsList() || fd.IsMap() || wellKnownFiles[fd.Message().ParentFile().Path()] {
+		return false
+	}
+	if to.Kind() == reflect.Pointer {
+		to = to.Elem()
+	}
+	return to.Kind() == reflect.Struct && !m.converters[typePair{src: from, dst: to}] && !m.converters[typePair{src: from, dst: reflect.PointerTo(to)}]
+}
+func (m *merger) nestedMessage(to reflect.Value, msg protoreflect.Message, value reflect.Value, mask fieldMaskTree, prefix string) ([]string, error) {
+	if to.Kind() != reflect.Pointer {
+		return m.message(to, msg, value, mask, prefix)
+	}
+	if !to.IsNil() {
+		return m.message(to.Elem(), msg, value, mask, prefix)
+	}
+	allocated := reflect.New(to.Type().Elem())
+	changed, err := m.message(allocated.Elem(), msg, value, mask, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if len(changed) > 0 {
+		to.Set(allocated)
+	}
+	return changed, nil
+}
+
+// mergeMap sets every key of from into to, keeping keys absent in from
+func (m *merger) mergeMap(to, from reflect.Value) (bool, error) {
+	converted, err := m.convert(from, to.Type())
+	if err != nil {
+		return false, err
+	}
+	changed := false
+	iter := converted.MapRange()
+	for iter.Next() {
+		if old := to.MapIndex(iter.Key()); old.IsValid() && reflect.DeepEqual(old.Interface(), iter.Value().Interface()) {
+			continue
+		}
+		if to.IsNil() {
+			to.Set(reflect.MakeMapWithSize(to.Type(), converted.Len()))
+		}
+		to.SetMapIndex(iter.Key(), iter.Value())
+		changed = true
+	}
+	return changed, nil
+}
+
+// convert copies value into new value of type t through single-field wrappers, so converters and nested rules apply
+func (m *merger) convert(from reflect.Value, t reflect.Type) (reflect.Value, error) {
+	srcWrapper := reflect.New(reflect.StructOf([]reflect.StructField{{Name: "Src", Type: from.Type()}}))
+	srcWrapper.Elem().Field(0).Set(from)
+	dstWrapper := reflect.New(reflect.StructOf([]reflect.StructField{{Name: "Dst", Type: t}}))
+
+	opt := m.opt
+	opt.FieldNameMapping = append(append([]copier.FieldNameMapping{}, m.opt.FieldNameMapping...), copier.FieldNameMapping{
+		SrcType: srcWrapper.Elem().Interface(),
+		DstType: dstWrapper.Elem().Interface(),
+		Mapping: map[string]string{"Src": "Dst"},
+	})
+	if err := copier.CopyWithOption(dstWrapper.Interface(), srcWrapper.Interface(), opt); err != nil {
+		return reflect.Value{}, err
+	}
+	return dstWrapper.Elem().Field(0), nil
+}
+
+// goField finds generated struct field holding fd, including fields wrapped by oneof
+func goField(message reflect.Value, fd protoreflect.FieldDescriptor) (reflect.Value, string, bool) {
+	t := message.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if oneof := fd.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() {
+			if f.Tag.Get("protobuf_oneof") != string(oneof.Name()) || message.Field(i).IsNil() {
+				continue
+			}
+			wrapper := message.Field(i).Elem().Elem()
+			if protoName(wrapper.Type().Field(0).Tag) != string(fd.Name()) {
+				return reflect.Value{}, "", false
+			}
+			return wrapper.Field(0), wrapper.Type().Field(0).Name, true
+		}
+		if protoName(f.Tag) == string(fd.Name()) {
+			return message.Field(i), f.Name, true
+		}
+	}
+	return reflect.Value{}, "", false
+}
+func protoName(tag reflect.StructTag) string {
+	for _, part := range strings.Split(tag.Get("protobuf"), ",") {
+		if name, ok := strings.CutPrefix(part, "name="); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// fieldMaskTree holds field mask paths by segments. Nil subtree means the whole field is masked
+type fieldMaskTree map[string]fieldMaskTree
+
+func newFieldMaskTree(paths []string) fieldMaskTree {
+	tree := make(fieldMaskTree)
+	for _, path := range paths {
+		node := tree
+		segments := strings.Split(path, ".")
+		for i, segment := range segments {
+			sub, ok := node[segment]
+			if ok && sub == nil {
+				break
+			}
+			if i == len(segments)-1 {
+				node[segment] = nil
+				break
+			}
+			if !ok {
+				sub = make(fieldMaskTree)
+				node[segment] = sub
+			}
+			node = sub
+		}
+	}
+	return tree
+}
@@ -0,0 +1,114 @@
+package copier
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+type mergeFieldOptions struct {
+	Packed     bool
+	Deprecated bool
+}
+type mergeField struct {
+	Name     string
+	Number   int32
+	JsonName string
+	Options  *mergeFieldOptions
+}
+type mergeErrorInfo struct {
+	Reason   string
+	Domain   string
+	Metadata map[string]string
+}
+
+func TestMergePresence(t *testing.T) {
+	table := []struct {
+		Name     string
+		Stored   mergeField
+		Patch    *descriptorpb.FieldDescriptorProto
+		Options  []option
+		Excepted mergeField
+		Changed  []string
+	}{
+		{"explicit zero", mergeField{Name: "old", Number: 5, JsonName: "keep"}, &descriptorpb.FieldDescriptorProto{Name: proto.String("title"), Number: proto.Int32(0)}, nil,
+			mergeField{Name: "title", JsonName: "keep"}, []string{"name", "number"}},
+		{"unchanged value", mergeField{Name: "title"}, &descriptorpb.FieldDescriptorProto{Name: proto.String("title")}, nil,
+			mergeField{Name: "title"}, nil},
+		{"nested merge", mergeField{Name: "title", Options: &mergeFieldOptions{Packed: true}}, &descriptorpb.FieldDescriptorProto{Options: &descriptorpb.FieldOptions{Deprecated: proto.Bool(true)}}, nil,
+			mergeField{Name: "title", Options: &mergeFieldOptions{Packed: true, Deprecated: true}}, []string{"options.deprecated"}},
+		{"nested allocation", mergeField{}, &descriptorpb.FieldDescriptorProto{Options: &descriptorpb.FieldOptions{Packed: proto.Bool(true)}}, nil,
+			mergeField{Options: &mergeFieldOptions{Packed: true}}, []string{"options.packed"}},
+		{"empty nested message", mergeField{}, &descriptorpb.FieldDescriptorProto{Options: &descriptorpb.FieldOptions{}}, nil,
+			mergeField{}, nil},
+		{"field mask", mergeField{Name: "old", JsonName: "old", Options: &mergeFieldOptions{Packed: true}}, &descriptorpb.FieldDescriptorProto{Name: proto.String("title")},
+			[]option{WithFieldMask(&fieldmaskpb.FieldMask{Paths: []string{"json_name", "options"}})},
+			mergeField{Name: "old"}, []string{"json_name", "options"}},
+		{"nested field mask", mergeField{Options: &mergeFieldOptions{Packed: true, Deprecated: true}}, &descriptorpb.FieldDescriptorProto{Options: &descriptorpb.FieldOptions{Packed: proto.Bool(true)}},
+			[]option{WithFieldMask(&fieldmaskpb.FieldMask{Paths: []string{"options.deprecated", "options.packed"}})},
+			mergeField{Options: &mergeFieldOptions{Packed: true}}, []string{"options.deprecated"}},
+		{"nested field mask of unset message", mergeField{Options: &mergeFieldOptions{Packed: true, Deprecated: true}}, &descriptorpb.FieldDescriptorProto{},
+			[]option{WithFieldMask(&fieldmaskpb.FieldMask{Paths: []string{"options.deprecated"}})},
+			mergeField{Options: &mergeFieldOptions{Packed: true}}, []string{"options.deprecated"}},
+		{"nested field mask of unset message and destination", mergeField{}, &descriptorpb.FieldDescriptorProto{},
+			[]option{WithFieldMask(&fieldmaskpb.FieldMask{Paths: []string{"options.deprecated"}})},
+			mergeField{}, nil},
+		{"ignored field", mergeField{Name: "old"}, &descriptorpb.FieldDescriptorProto{Name: proto.String("title"), Number: proto.Int32(2)}, []option{WithIgnoreFields("Name")},
+			mergeField{Name: "old", Number: 2}, []string{"number"}},
+	}
+	for _, v := range table {
+		t.Run(v.Name, func(t *testing.T) {
+			stored := v.Stored
+			changed, err := Merge(&stored, v.Patch, v.Options...)
+
+			assert.NoError(t, err)
+			assert.Equal(t, v.Excepted, stored)
+			assert.Equal(t, v.Changed, changed)
+		})
+	}
+}
+func TestMergeMap(t *testing.T) {
+	stored := mergeErrorInfo{Reason: "EXPIRED", Domain: "users", Metadata: map[string]string{"user": "1", "role": "admin"}}
+	changed, err := Merge(&stored, &errdetails.ErrorInfo{Metadata: map[string]string{"role": "user", "login": "reader"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"metadata"}, changed)
+	assert.Equal(t, mergeErrorInfo{Reason: "EXPIRED", Domain: "users", Metadata: map[string]string{"user": "1", "role": "user", "login": "reader"}}, stored)
+
+	changed, err = Merge(&stored, &errdetails.ErrorInfo{Metadata: map[string]string{"login": "reader"}}, WithFieldMask(&fieldmaskpb.FieldMask{Paths: []string{"reason", "metadata"}}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"reason", "metadata"}, changed)
+	assert.Equal(t, mergeErrorInfo{Domain: "users", Metadata: map[string]string{"login": "reader"}}, stored)
+}
+func TestMergeConverters(t *testing.T) {
+	type document struct {
+		Code    string
+		OwnerId primitive.ObjectID
+	}
+	id := primitive.NewObjectID()
+	options := []option{WithPrimitiveToStringConverter, WithFieldMapping("Reason", "Code"), WithFieldMapping("Domain", "OwnerId")}
+
+	var stored document
+	changed, err := Merge(&stored, &errdetails.ErrorInfo{Reason: "EXPIRED", Domain: id.Hex()}, options...)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"reason", "domain"}, changed)
+	assert.Equal(t, document{Code: "EXPIRED", OwnerId: id}, stored)
+
+	_, err = Merge(&stored, &errdetails.ErrorInfo{Domain: "not an id"}, options...)
+	assert.ErrorContains(t, err, "domain: ")
+}
+func TestMergeErrors(t *testing.T) {
+	var stored mergeField
+	_, err := Merge(stored, &descriptorpb.FieldDescriptorProto{})
+	assert.Error(t, err)
+
+	_, err = Merge(&stored, (*descriptorpb.FieldDescriptorProto)(nil))
+	assert.Error(t, err)
+
+	_, err = Merge(&stored, &descriptorpb.FieldDescriptorProto{}, WithFieldMask(&fieldmaskpb.FieldMask{Paths: []string{"title"}}))
+	assert.Error(t, err)
+}
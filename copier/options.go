@@ -5,21 +5,39 @@ import (
 
 	"github.com/jinzhu/copier"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
-type option func(*copier.Option)
+type options struct {
+	copier.Option
+	fieldMappings   []fieldMapping
+	ignoreFields    []string
+	caseInsensitive bool
+	fieldMask       *fieldmaskpb.FieldMask
+	strict          bool
+	report          *StrictReport
+}
+type option func(*options)
 
-func copyOption(opt ...option) copier.Option {
-	var option copier.Option
+func copyOption(opt ...option) *options {
+	var option options
 
 	for _, v := range opt {
 		v(&option)
 	}
-	return option
+	return &option
+}
+
+// WithCopierOption applies option written against jinzhu/copier, e.g. func(o *copier.Option) { o.DeepCopy = true }.
+// Such functions used to be passed directly, before options got settings of their own
+func WithCopierOption(fn func(*copier.Option)) option {
+	return func(c *options) {
+		fn(&c.Option)
+	}
 }
 
 var (
-	WithPrimitiveToStringConverter = func(c *copier.Option) {
+	WithPrimitiveToStringConverter = func(c *options) {
 		c.Converters = append(c.Converters, copier.TypeConverter{SrcType: primitive.ObjectID{}, DstType: string(""), Fn: func(src any) (dst any, err error) {
 			s, ok := src.(primitive.ObjectID)
 			if !ok {
@@ -66,7 +84,7 @@ var (
 			return result, nil
 		}})
 	}
-	WithIgnoreEmptyFields = func(c *copier.Option) {
+	WithIgnoreEmptyFields = func(c *options) {
 		c.IgnoreEmpty = true
 	}
 )
@@ -0,0 +1,292 @@
+package copier
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/jinzhu/copier"
+)
+
+// StrictReport lists field paths found by strict checks. Unmapped paths are destination ones,
+// dropped are source ones and truncated are destination ones with slice indexes and map keys
+type StrictReport struct {
+	Unmapped  []string
+	Dropped   []string
+	Truncated []string
+}
+
+func (r *StrictReport) Empty() bool {
+	return len(r.Unmapped) == 0 && len(r.Dropped) == 0 && len(r.Truncated) == 0
+}
+func (r *StrictReport) Error() string {
+	var parts []string
+	if len(r.Unmapped) > 0 {
+		parts = append(parts, fmt.Sprintf("unmapped destination fields: %s", strings.Join(r.Unmapped, ", ")))
+	}
+	if len(r.Dropped) > 0 {
+		parts = append(parts, fmt.Sprintf("dropped source fields: %s", strings.Join(r.Dropped, ", ")))
+	}
+	if len(r.Truncated) > 0 {
+		parts = append(parts, fmt.Sprintf("truncated values: %s", strings.Join(r.Truncated, ", ")))
+	}
+	return "strict copy failed: " + strings.Join(parts, "; ")
+}
+
+// WithStrict makes copy fail with *StrictReport error when destination fields stay unmapped, source fields are dropped
+// or numeric values are truncated. Nothing is copied in this case. Fields excluded by WithIgnoreFields or copier:"-" tag are not reported
+func WithStrict() option {
+	return func(c *options) {
+		c.strict = true
+	}
+}
+
+// WithStrictReport fills report with the same checks as WithStrict, but doesn't fail the copy.
+// Report isn't synchronized and is overwritten by every copy made with the option, so it's meant for a single copy.
+// Mapper shared between goroutines should use WithStrict instead, which returns report of each copy as error
+func WithStrictReport(report *StrictReport) option {
+	return func(c *options) {
+		c.report = report
+	}
+}
+
+// checkStrict runs strict checks before copying src into dst type if they are enabled
+func (o *options) checkStrict(dstType reflect.Type, src reflect.Value, opt copier.Option) error {
+	if !o.strict && o.report == nil {
+		return nil
+	}
+	checker := newStrictChecker(opt)
+	if dstType != nil && src.IsValid() {
+		checker.fields("", "", dstType, src.Type(), make(map[typePair]bool))
+		checker.values("", dstType, src)
+	}
+	if o.report != nil {
+		*o.report = checker.report
+	}
+	if o.strict && !checker.report.Empty() {
+		report := checker.report
+		return &report
+	}
+	return nil
+}
+
+type strictChecker struct {
+	opt        copier.Option
+	names      map[typePair]map[string]string
+	converters map[typePair]bool
+	report     StrictReport
+}
+
+type fieldPair struct {
+	src, dst reflect.StructField
+}
+
+func newStrictChecker(opt copier.Option) *strictChecker {
+	c := &strictChecker{opt: opt, names: make(map[typePair]map[string]string), converters: make(map[typePair]bool)}
+	for _, mapping := range opt.FieldNameMapping {
+		c.names[typePair{src: reflect.TypeOf(mapping.SrcType), dst: reflect.TypeOf(mapping.DstType)}] = mapping.Mapping
+	}
+	for _, converter := range opt.Converters {
+		c.converters[typePair{src: reflect.TypeOf(converter.SrcType), dst: reflect.TypeOf(converter.DstType)}] = true
+	}
+	return c
+}
+
+// walkable reports whether copier copies src into dst field by field
+func (c *strictChecker) walkable(src, dst reflect.Type) bool {
+	if c.converters[typePair{src: src, dst: dst}] {
+		return false
+	}
+	src, dst = structType(src), structType(dst)
+	if src.Kind() != reflect.Struct || dst.Kind() != reflect.Struct || src == dst {
+		return false
+	}
+	// copier converts whole struct at once if any converter is registered
+	return len(c.converters) == 0 || !src.ConvertibleTo(dst)
+}
+
+// fields reports unmapped and dropped fields of src and dst types and their nested structs
+func (c *strictChecker) fields(srcPrefix, dstPrefix string, dst, src reflect.Type, visited map[typePair]bool) {
+	if !c.walkable(src, dst) {
+		return
+	}
+	pair := typePair{src: structType(src), dst: structType(dst)}
+	if visited[pair] {
+		return
+	}
+	visited[pair] = true
+	defer delete(visited, pair)
+
+	pairs, dropped, unmapped := c.match(pair)
+	for _, name := range dropped {
+		c.report.Dropped = append(c.report.Dropped, srcPrefix+name)
+	}
+	for _, name := range unmapped {
+		c.report.Unmapped = append(c.report.Unmapped, dstPrefix+name)
+	}
+	for _, p := range pairs {
+		c.fields(srcPrefix+p.src.Name+".", dstPrefix+p.dst.Name+".", p.dst.Type, p.src.Type, visited)
+	}
+}
+
+// values reports numeric values of src which don't fit into dst type
+func (c *strictChecker) values(path string, dst reflect.Type, src reflect.Value) {
+	if c.converters[typePair{src: src.Type(), dst: dst}] {
+		return
+	}
+	for src.Kind() == reflect.Pointer || src.Kind() == reflect.Interface {
+		if src.IsNil() {
+			return
+		}
+		src = src.Elem()
+	}
+	for dst.Kind() == reflect.Pointer {
+		dst = dst.Elem()
+	}
+	switch {
+	case isNumber(src.Kind()) && isNumber(dst.Kind()):
+		if truncates(src, dst) {
+			c.report.Truncated = append(c.report.Truncated, path)
+		}
+	case (src.Kind() == reflect.Slice || src.Kind() == reflect.Array) && (dst.Kind() == reflect.Slice || dst.Kind() == reflect.Array):
+		for i := 0; i < src.Len(); i++ {
+			c.values(fmt.Sprintf("%s[%d]", path, i), dst.Elem(), src.Index(i))
+		}
+	case src.Kind() == reflect.Map && dst.Kind() == reflect.Map:
+		iter := src.MapRange()
+		for iter.Next() {
+			c.values(fmt.Sprintf("%s[%v]", path, iter.Key()), dst.Elem(), iter.Value())
+		}
+	case src.Kind() == reflect.Struct && c.walkable(src.Type(), dst):
+		pairs, _, _ := c.match(typePair{src: src.Type(), dst: dst})
+		for _, p := range pairs {
+			value, err := src.FieldByIndexErr(p.src.Index)
+			if err != nil {
+				continue
+			}
+			name := p.dst.Name
+			if path != "" {
+				name = path + "." + name
+			}
+			c.values(name, p.dst.Type, value)
+		}
+	}
+}
+
+// match pairs fields the way copier does: by mapping, copier tag names and then by field name
+func (c *strictChecker) match(pair typePair) (pairs []fieldPair, dropped, unmapped []string) {
+	names := c.names[pair]
+	dstFields := flatFields(pair.dst)
+	matched := make(map[string]bool)
+	for _, target := range names {
+		if ignored, ok := strings.CutPrefix(target, ignoredFieldPrefix); ok {
+			matched[ignored] = true
+		}
+	}
+
+	for _, from := range flatFields(pair.src) {
+		if ignoredTag(from) {
+			continue
+		}
+		target := targetName(from.Name, names)
+		if strings.HasPrefix(target, ignoredFieldPrefix) {
+			continue
+		}
+		if _, ok := names[from.Name]; !ok {
+			if name := tagName(from); name != "" {
+				target = name
+			}
+		}
+		to, ok := c.destination(dstFields, target)
+		if !ok {
+			if _, ok := reflect.PointerTo(pair.dst).MethodByName(target); !ok {
+				dropped = append(dropped, from.Name)
+			}
+			continue
+		}
+		matched[to.Name] = true
+		if !ignoredTag(to) {
+			pairs = append(pairs, fieldPair{src: from, dst: to})
+		}
+	}
+	for _, to := range dstFields {
+		if matched[to.Name] || ignoredTag(to) {
+			continue
+		}
+		if method, ok := reflect.PointerTo(pair.src).MethodByName(to.Name); ok && method.Type.NumIn() == 1 && method.Type.NumOut() == 1 {
+			continue
+		}
+		unmapped = append(unmapped, to.Name)
+	}
+	return pairs, dropped, unmapped
+}
+func (c *strictChecker) destination(fields []reflect.StructField, name string) (reflect.StructField, bool) {
+	for _, f := range fields {
+		if tagName(f) == name {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if f.Name == name || !c.opt.CaseSensitive && strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// flatFields returns exported fields including fields of embedded structs instead of embedded structs themselves
+func flatFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			if embedded := structType(f.Type); embedded.Kind() == reflect.Struct {
+				for _, inner := range flatFields(embedded) {
+					inner.Index = append([]int{i}, inner.Index...)
+					fields = append(fields, inner)
+				}
+				continue
+			}
+		}
+		if f.IsExported() {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+func ignoredTag(f reflect.StructField) bool {
+	return f.Tag.Get("copier") == "-"
+}
+func tagName(f reflect.StructField) string {
+	for _, part := range strings.Split(f.Tag.Get("copier"), ",") {
+		if part != "" && part != "-" && part != "must" && part != "nopanic" {
+			return strings.TrimSpace(part)
+		}
+	}
+	return ""
+}
+func isNumber(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Float64
+}
+
+// truncates reports whether numeric value changes after conversion to t. Floats lose precision by design, so only overflow is reported for them
+func truncates(v reflect.Value, t reflect.Type) bool {
+	converted := v.Convert(t)
+	switch {
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		if v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64 {
+			return !math.IsInf(v.Float(), 0) && math.IsInf(converted.Float(), 0)
+		}
+		return converted.Convert(v.Type()).Interface() != v.Interface()
+	case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
+		f := v.Float()
+		return math.IsNaN(f) || math.Trunc(f) != f || converted.Convert(v.Type()).Float() != f
+	case v.CanInt() && converted.CanUint():
+		return v.Int() < 0 || converted.Convert(v.Type()).Int() != v.Int()
+	case v.CanUint() && converted.CanInt():
+		return converted.Int() < 0 || converted.Convert(v.Type()).Uint() != v.Uint()
+	default:
+		return converted.Convert(v.Type()).Interface() != v.Interface()
+	}
+}
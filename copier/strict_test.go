@@ -0,0 +1,119 @@
+package copier
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type strictGenre struct {
+	Name string
+	Slug string
+}
+type strictModel struct {
+	Id        primitive.ObjectID
+	Title     string
+	AuthorIds []primitive.ObjectID
+	Pages     int64
+	Rating    float64
+	Genre     strictGenre
+	Internal  string `copier:"-"`
+}
+type strictGenreMessage struct {
+	Name  string
+	Title string
+}
+type strictMessage struct {
+	Id      string
+	Title   string
+	Authors []string
+	Pages   int32
+	Rating  int
+	Genre   *strictGenreMessage
+}
+
+func TestStrictCopy(t *testing.T) {
+	model := strictModel{Id: primitive.NewObjectID(), Title: "book", Pages: 320, Rating: 4, Genre: strictGenre{Name: "fantasy"}}
+
+	var message strictMessage
+	err := Copy(&message, &model, WithPrimitiveToStringConverter, WithStrict())
+	var report *StrictReport
+	assert.ErrorAs(t, err, &report)
+	assert.Equal(t, []string{"Authors", "Genre.Title"}, report.Unmapped)
+	assert.Equal(t, []string{"AuthorIds", "Genre.Slug"}, report.Dropped)
+	assert.Empty(t, report.Truncated)
+	assert.Equal(t, strictMessage{}, message)
+
+	options := []option{WithPrimitiveToStringConverter, WithStrict(), WithFieldMapping("AuthorIds", "Authors"), WithFieldMapping("Genre.Slug", "Genre.Title")}
+	assert.NoError(t, Copy(&message, &model, options...))
+	assert.Equal(t, "book", message.Title)
+
+	mapper, err := NewMapper[strictModel, strictMessage](options...)
+	assert.NoError(t, err)
+	_, err = mapper.Map(&model)
+	assert.NoError(t, err)
+
+	t.Run("ignored fields", func(t *testing.T) {
+		var message strictMessage
+		assert.NoError(t, Copy(&message, &model, WithPrimitiveToStringConverter, WithStrict(), WithIgnoreFields("Authors", "Genre.Title"), WithFieldMapping("AuthorIds", "Authors"), WithFieldMapping("Genre.Slug", "Genre.Title")))
+		assert.Empty(t, message.Authors)
+	})
+}
+func TestStrictTruncation(t *testing.T) {
+	options := []option{WithPrimitiveToStringConverter, WithStrict(), WithFieldMapping("AuthorIds", "Authors"), WithFieldMapping("Genre.Slug", "Genre.Title")}
+	table := []struct {
+		Name     string
+		Pages    int64
+		Rating   float64
+		Excepted []string
+	}{
+		{"fits", 320, 4, nil},
+		{"integer overflow", math.MaxInt32 + 1, 4, []string{"Pages"}},
+		{"fraction", 320, 4.5, []string{"Rating"}},
+		{"both", math.MinInt64, math.NaN(), []string{"Pages", "Rating"}},
+	}
+	for _, v := range table {
+		t.Run(v.Name, func(t *testing.T) {
+			model := strictModel{Pages: v.Pages, Rating: v.Rating}
+
+			_, err := CopyTo[*strictMessage](&model, options...)
+			if v.Excepted == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var report *StrictReport
+			assert.ErrorAs(t, err, &report)
+			assert.Equal(t, v.Excepted, report.Truncated)
+		})
+	}
+
+	t.Run("slice indexes", func(t *testing.T) {
+		_, err := CopySlice[strictModel, strictMessage]([]strictModel{{Pages: 1}, {Pages: -1 << 40}}, options...)
+		var report *StrictReport
+		assert.ErrorAs(t, err, &report)
+		assert.Equal(t, []string{"[1].Pages"}, report.Truncated)
+	})
+	t.Run("nested values", func(t *testing.T) {
+		type counters struct {
+			Views  []uint64
+			Scores map[string]int
+		}
+		type message struct {
+			Views  []int64
+			Scores map[string]uint8
+		}
+		var report StrictReport
+		var result message
+		assert.NoError(t, Copy(&result, &counters{Views: []uint64{1, math.MaxUint64}, Scores: map[string]int{"a": 300}}, WithStrictReport(&report)))
+		assert.Equal(t, []string{"Views[1]", "Scores[a]"}, report.Truncated)
+		assert.Equal(t, []int64{1, -1}, result.Views)
+	})
+}
+func TestStrictReportError(t *testing.T) {
+	report := &StrictReport{Unmapped: []string{"Authors"}, Dropped: []string{"AuthorIds", "Genre.Slug"}, Truncated: []string{"Pages"}}
+	assert.EqualError(t, report, "strict copy failed: unmapped destination fields: Authors; dropped source fields: AuthorIds, Genre.Slug; truncated values: Pages")
+	assert.False(t, report.Empty())
+	assert.True(t, (&StrictReport{}).Empty())
+}
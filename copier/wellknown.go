@@ -0,0 +1,187 @@
+package copier
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/jinzhu/copier"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// typeConverters creates converters from S to D and from []S to []D. If S isn't a pointer, *S is converted to D and *D,
+// otherwise S is also converted to *D. Nil pointers are converted to zero value of D or nil *D
+func typeConverters[S, D any](fn func(S) (D, error)) []copier.TypeConverter {
+	var src S
+	var dst D
+	converters := []copier.TypeConverter{
+		{SrcType: src, DstType: dst, Fn: func(from any) (any, error) {
+			s, ok := from.(S)
+			if !ok {
+				return nil, fmt.Errorf("unable to convert %v to %T", from, dst)
+			}
+			return fn(s)
+		}},
+		{SrcType: []S{}, DstType: []D{}, Fn: func(from any) (any, error) {
+			s, ok := from.([]S)
+			if !ok {
+				return nil, fmt.Errorf("unable to convert %v to %T", from, []D{})
+			}
+			if s == nil {
+				return []D(nil), nil
+			}
+			result := make([]D, 0, len(s))
+			for _, i := range s {
+				d, err := fn(i)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, d)
+			}
+			return result, nil
+		}},
+	}
+	if reflect.TypeFor[S]().Kind() == reflect.Pointer {
+		if reflect.TypeFor[D]().Kind() == reflect.Pointer {
+			return converters
+		}
+		return append(converters, copier.TypeConverter{SrcType: src, DstType: &dst, Fn: func(from any) (any, error) {
+			s, ok := from.(S)
+			if !ok {
+				return nil, fmt.Errorf("unable to convert %v to %T", from, &dst)
+			}
+			if reflect.ValueOf(s).IsNil() {
+				return (*D)(nil), nil
+			}
+			d, err := fn(s)
+			if err != nil {
+				return nil, err
+			}
+			return &d, nil
+		}})
+	}
+	converters = append(converters, copier.TypeConverter{SrcType: &src, DstType: dst, Fn: func(from any) (any, error) {
+		s, ok := from.(*S)
+		if !ok {
+			return nil, fmt.Errorf("unable to convert %v to %T", from, dst)
+		}
+		if s == nil {
+			var zero D
+			return zero, nil
+		}
+		return fn(*s)
+	}})
+	if reflect.TypeFor[D]().Kind() == reflect.Pointer {
+		return converters
+	}
+	return append(converters, copier.TypeConverter{SrcType: &src, DstType: &dst, Fn: func(from any) (any, error) {
+		s, ok := from.(*S)
+		if !ok {
+			return nil, fmt.Errorf("unable to convert %v to %T", from, &dst)
+		}
+		if s == nil {
+			return (*D)(nil), nil
+		}
+		d, err := fn(*s)
+		if err != nil {
+			return nil, err
+		}
+		return &d, nil
+	}})
+}
+
+// bidirectional registers converters for both directions
+func bidirectional[A, B any](to func(A) (B, error), from func(B) (A, error)) []copier.TypeConverter {
+	return append(typeConverters(to), typeConverters(from)...)
+}
+
+// wrapperConverters converts between plain value and protobuf wrapper
+func wrapperConverters[T any, W interface{ GetValue() T }](wrap func(T) W) []copier.TypeConverter {
+	return bidirectional(func(v T) (W, error) { return wrap(v), nil }, func(w W) (T, error) { return w.GetValue(), nil })
+}
+func timeToTimestamp(t time.Time) (*timestamppb.Timestamp, error) {
+	if t.IsZero() {
+		return nil, nil
+	}
+	return timestamppb.New(t), nil
+}
+func timestampToTime(t *timestamppb.Timestamp) (time.Time, error) {
+	if t == nil {
+		return time.Time{}, nil
+	}
+	if err := t.CheckValid(); err != nil {
+		return time.Time{}, err
+	}
+	return t.AsTime(), nil
+}
+
+var wellKnownConverters = func() []copier.TypeConverter {
+	var converters []copier.TypeConverter
+
+	converters = append(converters, bidirectional(timeToTimestamp, timestampToTime)...)
+	converters = append(converters, bidirectional(
+		func(d primitive.DateTime) (*timestamppb.Timestamp, error) {
+			return timestamppb.New(d.Time()), nil
+		},
+		func(t *timestamppb.Timestamp) (primitive.DateTime, error) {
+			if t == nil {
+				return 0, nil
+			}
+			if err := t.CheckValid(); err != nil {
+				return 0, err
+			}
+			return primitive.NewDateTimeFromTime(t.AsTime()), nil
+		})...)
+	converters = append(converters, bidirectional(
+		func(d primitive.DateTime) (time.Time, error) {
+			return d.Time(), nil
+		},
+		func(t time.Time) (primitive.DateTime, error) {
+			return primitive.NewDateTimeFromTime(t), nil
+		})...)
+	converters = append(converters, bidirectional(
+		func(d primitive.Decimal128) (string, error) {
+			return d.String(), nil
+		},
+		func(s string) (primitive.Decimal128, error) {
+			if s == "" {
+				return primitive.Decimal128{}, nil
+			}
+			return primitive.ParseDecimal128(s)
+		})...)
+	converters = append(converters, bidirectional(
+		func(d time.Duration) (*durationpb.Duration, error) {
+			return durationpb.New(d), nil
+		},
+		func(d *durationpb.Duration) (time.Duration, error) {
+			if d == nil {
+				return 0, nil
+			}
+			if err := d.CheckValid(); err != nil {
+				return 0, err
+			}
+			return d.AsDuration(), nil
+		})...)
+
+	converters = append(converters, wrapperConverters(wrapperspb.String)...)
+	converters = append(converters, wrapperConverters(wrapperspb.Bool)...)
+	converters = append(converters, wrapperConverters(wrapperspb.Int32)...)
+	converters = append(converters, wrapperConverters(wrapperspb.Int64)...)
+	converters = append(converters, wrapperConverters(wrapperspb.UInt32)...)
+	converters = append(converters, wrapperConverters(wrapperspb.UInt64)...)
+	converters = append(converters, wrapperConverters(wrapperspb.Float)...)
+	converters = append(converters, wrapperConverters(wrapperspb.Double)...)
+	converters = append(converters, wrapperConverters(wrapperspb.Bytes)...)
+	return converters
+}()
+
+var (
+	// WithProtoWellKnownTypes converts time.Time, primitive.DateTime, primitive.Decimal128, time.Duration and
+	// plain values to protobuf Timestamp, Duration, wrappers and string and back, including slices and pointers
+	WithProtoWellKnownTypes = func(c *options) {
+		c.Converters = append(c.Converters, wellKnownConverters...)
+	}
+)
@@ -0,0 +1,117 @@
+package copier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type wellKnownModel struct {
+	Created   time.Time
+	Updated   *time.Time
+	Deleted   time.Time
+	Published primitive.DateTime
+	History   []time.Time
+	Price     primitive.Decimal128
+	Timeout   time.Duration
+	Title     string
+	Subtitle  *string
+	Pages     int32
+	Rating    float64
+	Free      *bool
+}
+type wellKnownMessage struct {
+	Created   *timestamppb.Timestamp
+	Updated   *timestamppb.Timestamp
+	Deleted   *timestamppb.Timestamp
+	Published *timestamppb.Timestamp
+	History   []*timestamppb.Timestamp
+	Price     string
+	Timeout   *durationpb.Duration
+	Title     *wrapperspb.StringValue
+	Subtitle  *wrapperspb.StringValue
+	Pages     *wrapperspb.Int32Value
+	Rating    *wrapperspb.DoubleValue
+	Free      *wrapperspb.BoolValue
+}
+
+func TestProtoWellKnownTypes(t *testing.T) {
+	now := time.Date(2025, 3, 16, 17, 44, 53, 0, time.UTC)
+	later := now.Add(time.Hour)
+	price, _ := primitive.ParseDecimal128("199.99")
+	subtitle := "subtitle"
+
+	model := wellKnownModel{
+		Created:   now,
+		Updated:   &later,
+		Published: primitive.NewDateTimeFromTime(now),
+		History:   []time.Time{now, later},
+		Price:     price,
+		Timeout:   5 * time.Second,
+		Title:     "title",
+		Subtitle:  &subtitle,
+		Pages:     320,
+		Rating:    4.5,
+	}
+	var message wellKnownMessage
+	err := Copy(&message, &model, WithProtoWellKnownTypes)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.True(t, now.Equal(message.Created.AsTime()))
+	assert.True(t, later.Equal(message.Updated.AsTime()))
+	assert.Nil(t, message.Deleted)
+	assert.True(t, now.Equal(message.Published.AsTime()))
+	if assert.Len(t, message.History, 2) {
+		assert.True(t, later.Equal(message.History[1].AsTime()))
+	}
+	assert.Equal(t, "199.99", message.Price)
+	assert.Equal(t, 5*time.Second, message.Timeout.AsDuration())
+	assert.Equal(t, "title", message.Title.GetValue())
+	assert.Equal(t, "subtitle", message.Subtitle.GetValue())
+	assert.EqualValues(t, 320, message.Pages.GetValue())
+	assert.Equal(t, 4.5, message.Rating.GetValue())
+	assert.Nil(t, message.Free)
+
+	var restored wellKnownModel
+	err = Copy(&restored, &message, WithProtoWellKnownTypes)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, now.Equal(restored.Created))
+	assert.True(t, later.Equal(*restored.Updated))
+	assert.True(t, restored.Deleted.IsZero())
+	assert.Equal(t, model.Published, restored.Published)
+	assert.Equal(t, model.Price.String(), restored.Price.String())
+	assert.Equal(t, model.Timeout, restored.Timeout)
+	assert.Equal(t, "title", restored.Title)
+	assert.Equal(t, "subtitle", *restored.Subtitle)
+	assert.EqualValues(t, 320, restored.Pages)
+	assert.Nil(t, restored.Free)
+	if assert.Len(t, restored.History, 2) {
+		assert.True(t, now.Equal(restored.History[0]))
+	}
+}
+func TestProtoWellKnownTypesErrors(t *testing.T) {
+	message := struct {
+		Price string
+	}{Price: "not a number"}
+	model := struct {
+		Price primitive.Decimal128
+	}{}
+	assert.Error(t, Copy(&model, &message, WithProtoWellKnownTypes))
+
+	invalid := struct {
+		Created *timestamppb.Timestamp
+	}{Created: &timestamppb.Timestamp{Nanos: -1}}
+	created := struct {
+		Created time.Time
+	}{}
+	assert.Error(t, Copy(&created, &invalid, WithProtoWellKnownTypes))
+}
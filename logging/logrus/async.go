@@ -0,0 +1,156 @@
+package logrus
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DropPolicy decides what AsyncHook does when its buffer is full
+type DropPolicy int
+
+const (
+	// Block waits until buffer has free space
+	Block DropPolicy = iota
+	// DropOldest replaces the oldest buffered entry
+	DropOldest
+	// DropNewest discards the fired entry
+	DropNewest
+)
+
+func ParseDropPolicy(policy string) (DropPolicy, error) {
+	switch strings.ToLower(policy) {
+	case "", "block":
+		return Block, nil
+	case "oldest":
+		return DropOldest, nil
+	case "newest":
+		return DropNewest, nil
+	default:
+		return Block, fmt.Errorf("unknown drop policy %s", policy)
+	}
+}
+
+// AsyncHook fires wrapped hook from background goroutine, so slow writers don't stall logging goroutines.
+// Fatal and panic entries are fired synchronously after buffered ones, since process is terminated right after them
+type AsyncHook struct {
+	hook   logrus.Hook
+	policy DropPolicy
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	entries  []*logrus.Entry // ring buffer
+	head     int
+	count    int
+	inFlight bool
+	closed   bool
+	done     chan struct{}
+
+	dropped atomic.Uint64
+}
+
+func NewAsyncHook(hook logrus.Hook, size int, policy DropPolicy) *AsyncHook {
+	if size <= 0 {
+		size = 1
+	}
+	h := &AsyncHook{hook: hook, policy: policy, entries: make([]*logrus.Entry, size), done: make(chan struct{})}
+	h.cond = sync.NewCond(&h.mu)
+	go h.run()
+	return h
+}
+func (h *AsyncHook) Levels() []logrus.Level {
+	return h.hook.Levels()
+}
+func (h *AsyncHook) Fire(entry *logrus.Entry) error {
+	if entry.Level <= logrus.FatalLevel {
+		h.Flush()
+		return h.hook.Fire(entry)
+	}
+	// entry is reused by logrus after hooks are fired
+	e := entry.Dup()
+	e.Level, e.Message, e.Caller = entry.Level, entry.Message, entry.Caller
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return h.hook.Fire(e)
+	}
+	for h.count == len(h.entries) {
+		switch h.policy {
+		case DropNewest:
+			h.dropped.Add(1)
+			return nil
+		case DropOldest:
+			h.entries[h.head] = nil
+			h.head = (h.head + 1) % len(h.entries)
+			h.count--
+			h.dropped.Add(1)
+		default:
+			h.cond.Wait()
+			if h.closed {
+				return h.hook.Fire(e)
+			}
+		}
+	}
+	h.entries[(h.head+h.count)%len(h.entries)] = e
+	h.count++
+	h.cond.Broadcast()
+	return nil
+}
+func (h *AsyncHook) run() {
+	defer close(h.done)
+	h.mu.Lock()
+	for {
+		for h.count == 0 && !h.closed {
+			h.cond.Wait()
+		}
+		if h.count == 0 {
+			h.mu.Unlock()
+			return
+		}
+		entry := h.entries[h.head]
+		h.entries[h.head] = nil
+		h.head = (h.head + 1) % len(h.entries)
+		h.count--
+		h.inFlight = true
+		h.cond.Broadcast()
+		h.mu.Unlock()
+
+		if err := h.hook.Fire(entry); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to fire hook: %v\n", err)
+		}
+
+		h.mu.Lock()
+		h.inFlight = false
+		h.cond.Broadcast()
+	}
+}
+
+// Dropped returns number of entries discarded because buffer was full
+func (h *AsyncHook) Dropped() uint64 {
+	return h.dropped.Load()
+}
+
+// Flush waits until all buffered entries are fired
+func (h *AsyncHook) Flush() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for h.count > 0 || h.inFlight {
+		h.cond.Wait()
+	}
+}
+
+// Close fires buffered entries and stops background goroutine. Entries fired after Close are fired synchronously
+func (h *AsyncHook) Close() error {
+	h.mu.Lock()
+	h.closed = true
+	h.cond.Broadcast()
+	h.mu.Unlock()
+
+	<-h.done
+	return nil
+}
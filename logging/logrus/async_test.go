@@ -0,0 +1,145 @@
+package logrus
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingHook struct {
+	mu       sync.Mutex
+	messages []string
+	started  chan struct{}
+	once     sync.Once
+	gate     chan struct{}
+}
+
+func newRecordingHook(blocked bool) *recordingHook {
+	h := &recordingHook{started: make(chan struct{})}
+	if blocked {
+		h.gate = make(chan struct{})
+	}
+	return h
+}
+func (h *recordingHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+func (h *recordingHook) Fire(entry *logrus.Entry) error {
+	h.once.Do(func() { close(h.started) })
+	if h.gate != nil {
+		<-h.gate
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.messages = append(h.messages, entry.Message)
+	return nil
+}
+func (h *recordingHook) recorded() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.messages...)
+}
+func fireMessages(t *testing.T, hook logrus.Hook, messages ...string) {
+	for _, message := range messages {
+		assert.NoError(t, hook.Fire(&logrus.Entry{Logger: logrus.New(), Level: logrus.InfoLevel, Message: message}))
+	}
+}
+
+func TestAsyncHookOrder(t *testing.T) {
+	inner := newRecordingHook(false)
+	hook := NewAsyncHook(inner, 8, Block)
+
+	var excepted []string
+	for i := 0; i < 100; i++ {
+		excepted = append(excepted, fmt.Sprint(i))
+	}
+	fireMessages(t, hook, excepted...)
+	hook.Flush()
+	assert.Equal(t, excepted, inner.recorded())
+	assert.Zero(t, hook.Dropped())
+	assert.NoError(t, hook.Close())
+}
+func TestAsyncHookDropPolicy(t *testing.T) {
+	table := []struct {
+		Name     string
+		Policy   DropPolicy
+		Excepted []string
+	}{
+		{"drop newest", DropNewest, []string{"1", "2", "3"}},
+		{"drop oldest", DropOldest, []string{"1", "4", "5"}},
+	}
+	for _, v := range table {
+		t.Run(v.Name, func(t *testing.T) {
+			inner := newRecordingHook(true)
+			hook := NewAsyncHook(inner, 2, v.Policy)
+
+			fireMessages(t, hook, "1")
+			<-inner.started
+			fireMessages(t, hook, "2", "3", "4", "5")
+			assert.EqualValues(t, 2, hook.Dropped())
+
+			close(inner.gate)
+			assert.NoError(t, hook.Close())
+			assert.Equal(t, v.Excepted, inner.recorded())
+		})
+	}
+}
+func TestAsyncHookBlock(t *testing.T) {
+	inner := newRecordingHook(true)
+	hook := NewAsyncHook(inner, 1, Block)
+	fireMessages(t, hook, "1")
+	<-inner.started
+	fireMessages(t, hook, "2")
+
+	fired := make(chan struct{})
+	go func() {
+		defer close(fired)
+		fireMessages(t, hook, "3")
+	}()
+	select {
+	case <-fired:
+		t.Fatal("fire returned while buffer is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(inner.gate)
+	<-fired
+	assert.NoError(t, hook.Close())
+	assert.Equal(t, []string{"1", "2", "3"}, inner.recorded())
+	assert.Zero(t, hook.Dropped())
+}
+func TestAsyncHookSynchronousFire(t *testing.T) {
+	inner := newRecordingHook(false)
+	hook := NewAsyncHook(inner, 8, Block)
+
+	fireMessages(t, hook, "1", "2")
+	assert.NoError(t, hook.Fire(&logrus.Entry{Logger: logrus.New(), Level: logrus.FatalLevel, Message: "fatal"}))
+	assert.Equal(t, []string{"1", "2", "fatal"}, inner.recorded())
+
+	assert.NoError(t, hook.Close())
+	fireMessages(t, hook, "after close")
+	assert.Equal(t, []string{"1", "2", "fatal", "after close"}, inner.recorded())
+}
+func TestAsyncLogger(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "service.log")
+	logger, err := NewLogger(&Config{Outputs: []string{OutputFile}, FilePath: file, Async: true, DropPolicy: "oldest"})
+	assert.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		logger.WithField("index", i).Info("entry")
+	}
+	assert.NoError(t, logger.Close())
+
+	content, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, strings.Count(string(content), "msg=entry"))
+
+	_, err = NewLogger(&Config{Async: true, DropPolicy: "random"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,204 @@
+package logrus
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+	OutputFile   = "file"
+
+	FormatText = "text"
+	FormatJSON = "json"
+
+	defaultLogsDirectory = "logs"
+	defaultBufferSize    = 1024
+)
+
+type Config struct {
+	Level    string   `env:"LOG_LEVEL" env-default:"trace" env-description:"Minimal logged level: panic, fatal, error, warn, info, debug or trace"`
+	Format   string   `env:"LOG_FORMAT" env-default:"text" env-description:"Log entries format: text or json"`
+	Outputs  []string `env:"LOG_OUTPUTS" env-default:"file,stdout" env-separator:"," env-description:"Comma separated list of log outputs: stdout, stderr, file. Output may override level and format as output:level:format, e.g. file:info:json"`
+	FilePath string   `env:"LOG_FILE" env-description:"Log file path. If not provided, file named by start time is created in logs directory"`
+
+	MaxSize     int           `env:"LOG_MAX_SIZE" env-default:"100" env-description:"Log file size in megabytes to rotate it. Zero disables rotation by size"`
+	RotateEvery time.Duration `env:"LOG_ROTATE_EVERY" env-description:"Log file age to rotate it, e.g. 24h. Zero disables rotation by time"`
+	MaxAge      time.Duration `env:"LOG_MAX_AGE" env-description:"Age of rotated log files to remove them, e.g. 720h. Zero keeps files regardless of age"`
+	MaxFiles    int           `env:"LOG_MAX_FILES" env-default:"10" env-description:"Number of rotated log files to keep. Zero keeps all files"`
+	Compress    bool          `env:"LOG_COMPRESS" env-default:"true" env-description:"Compress rotated log files with gzip"`
+
+	DisableRedaction bool     `env:"LOG_DISABLE_REDACTION" env-description:"Write sensitive values such as passwords, tokens, cookies and emails as is"`
+	RedactFields     []string `env:"LOG_REDACT_FIELDS" env-separator:"," env-description:"Comma separated list of additional field names which values are masked"`
+
+	Async      bool   `env:"LOG_ASYNC" env-description:"Write log entries from background goroutine"`
+	BufferSize int    `env:"LOG_BUFFER_SIZE" env-default:"1024" env-description:"Number of entries buffered by asynchronous logging"`
+	DropPolicy string `env:"LOG_DROP_POLICY" env-default:"block" env-description:"Asynchronous logging behaviour on full buffer: block, oldest or newest to drop oldest or new entry"`
+}
+
+// NewLogger creates logger by config. Empty config values are replaced with defaults used by GetLogger
+func NewLogger(cfg *Config) (*Logger, error) {
+	if cfg == nil {
+		return nil, errors.New("received nil config")
+	}
+	level := logrus.TraceLevel
+	if cfg.Level != "" {
+		var err error
+		if level, err = logrus.ParseLevel(cfg.Level); err != nil {
+			return nil, fmt.Errorf("invalid log level: %w", err)
+		}
+	}
+	formatter, err := newFormatter(cfg.Format)
+	if err != nil {
+		return nil, err
+	}
+	dropPolicy, err := ParseDropPolicy(cfg.DropPolicy)
+	if err != nil {
+		return nil, err
+	}
+	outputs := cfg.Outputs
+	if len(outputs) == 0 {
+		outputs = []string{OutputFile, OutputStdout}
+	}
+
+	logger := &Logger{}
+	hookOutputs := make([]Output, 0, len(outputs))
+	loggerLevel := logrus.PanicLevel
+	for _, spec := range outputs {
+		output, name, err := parseOutput(spec, level)
+		if err != nil {
+			logger.Close()
+			return nil, err
+		}
+		switch name {
+		case OutputStdout:
+			output.Writer = os.Stdout
+		case OutputStderr:
+			output.Writer = os.Stderr
+		case OutputFile:
+			file, err := openLogFile(cfg)
+			if err != nil {
+				logger.Close()
+				return nil, err
+			}
+			file.ReopenOn(syscall.SIGHUP)
+			output.Writer = file
+			logger.closers = append(logger.closers, file)
+		default:
+			logger.Close()
+			return nil, fmt.Errorf("unknown log output %s", spec)
+		}
+		hookOutputs = append(hookOutputs, output)
+		loggerLevel = max(loggerLevel, output.Level)
+	}
+
+	l := logrus.New()
+	l.SetReportCaller(true)
+	l.Formatter = formatter
+	l.SetOutput(io.Discard)
+	var hook logrus.Hook = &Hook{
+		Outputs:   hookOutputs,
+		LogLevels: logrus.AllLevels,
+	}
+	if cfg.Async {
+		bufferSize := cfg.BufferSize
+		if bufferSize <= 0 {
+			bufferSize = defaultBufferSize
+		}
+		async := NewAsyncHook(hook, bufferSize, dropPolicy)
+		// buffered entries must be written before files are closed
+		logger.closers = append([]io.Closer{async}, logger.closers...)
+		hook = async
+	}
+	if !cfg.DisableRedaction {
+		l.AddHook(NewRedactor(append(append([]string{}, DefaultRedactedFields...), cfg.RedactFields...)...))
+	}
+	l.AddHook(hook)
+	// outputs filter entries by their own levels, so logger passes the most verbose of them
+	l.SetLevel(loggerLevel)
+
+	logger.Entry = logrus.NewEntry(l)
+	return logger, nil
+}
+
+// parseOutput reads output:level:format spec. Level and format are optional, omitted format means logger's one
+func parseOutput(spec string, level logrus.Level) (Output, string, error) {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(spec)), ":")
+	if len(parts) > 3 {
+		return Output{}, "", fmt.Errorf("invalid log output %s", spec)
+	}
+	output := Output{Level: level}
+	if len(parts) > 1 && parts[1] != "" {
+		var err error
+		if output.Level, err = logrus.ParseLevel(parts[1]); err != nil {
+			return Output{}, "", fmt.Errorf("invalid log output %s level: %w", spec, err)
+		}
+	}
+	if len(parts) > 2 && parts[2] != "" {
+		var err error
+		if output.Formatter, err = newFormatter(parts[2]); err != nil {
+			return Output{}, "", fmt.Errorf("invalid log output %s: %w", spec, err)
+		}
+	}
+	return output, parts[0], nil
+}
+func newFormatter(format string) (logrus.Formatter, error) {
+	switch strings.ToLower(format) {
+	case "", FormatText:
+		return &logrus.TextFormatter{
+			CallerPrettyfier: callerPrettyfier,
+			DisableColors:    false,
+			FullTimestamp:    true,
+		}, nil
+	case FormatJSON:
+		return &logrus.JSONFormatter{
+			CallerPrettyfier: callerPrettyfier,
+			TimestampFormat:  time.RFC3339Nano,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown log format %s", format)
+	}
+}
+func callerPrettyfier(f *runtime.Frame) (string, string) {
+	filename := path.Base(f.File)
+	return fmt.Sprintf("%s:%d", filename, f.Line), (f.Function + "()")
+}
+
+// openLogFile opens rotating log file. Without path file is named by start time in logs directory.
+// Files of previous starts are counted by retention as rotated ones, so restarting service can't fill the disk
+func openLogFile(cfg *Config) (*RotatingFile, error) {
+	policy := RotationPolicy{
+		MaxSize:  int64(cfg.MaxSize) << 20,
+		Interval: cfg.RotateEvery,
+		MaxAge:   cfg.MaxAge,
+		MaxFiles: cfg.MaxFiles,
+		Compress: cfg.Compress,
+	}
+	if cfg.FilePath != "" {
+		return NewRotatingFile(cfg.FilePath, policy)
+	}
+	return newRotatingFile(filepath.Join(defaultLogsDirectory, time.Now().UTC().Format(dateTimeLayout)+".log"), policy, startTimeNames)
+}
+
+// startTimeNames matches files named by start time, rotated ones included
+func startTimeNames(name string) bool {
+	if len(name) < len(dateTimeLayout) {
+		return false
+	}
+	start := name[:len(dateTimeLayout)]
+	if _, err := time.Parse(dateTimeLayout, start); err != nil {
+		return false
+	}
+	return name == start+".log" || rotatedNames(start+".log")(name)
+}
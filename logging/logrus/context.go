@@ -0,0 +1,79 @@
+package logrus
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Metadata keys set by middleware package, they must stay in sync with middleware.UserIdKey and middleware.UserLoginKey
+const (
+	userIdKey    = "userauthid"
+	userLoginKey = "userlogincredential"
+
+	RequestIdKey   = "x-request-id"
+	traceParentKey = "traceparent"
+	b3TraceIdKey   = "x-b3-traceid"
+	b3SpanIdKey    = "x-b3-spanid"
+)
+
+// Field names attached by WithContext
+const (
+	RequestIdField  = "request_id"
+	UserIdField     = "user_id"
+	UserLoginField  = "user_login"
+	GrpcMethodField = "grpc_method"
+	TraceIdField    = "trace_id"
+	SpanIdField     = "span_id"
+)
+
+// ContextWithRequestId puts request id into outgoing metadata, so it's logged by WithContext and passed to called services
+func ContextWithRequestId(ctx context.Context, requestId string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, RequestIdKey, requestId)
+}
+
+// WithContext returns logger which entries carry request id, user id and login, gRPC method and trace ids found in ctx.
+// Values are taken from incoming gRPC metadata first and then from outgoing one, e.g. set by JWT middleware
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	fields := logrus.Fields{}
+	incoming, _ := metadata.FromIncomingContext(ctx)
+	outgoing, _ := metadata.FromOutgoingContext(ctx)
+	lookup := func(key string) string {
+		if values := incoming.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		if values := outgoing.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	for key, field := range map[string]string{RequestIdKey: RequestIdField, userIdKey: UserIdField, userLoginKey: UserLoginField} {
+		if value := lookup(key); value != "" {
+			fields[field] = value
+		}
+	}
+	if method, ok := grpc.Method(ctx); ok {
+		fields[GrpcMethodField] = method
+	}
+	if traceId, spanId := parseTraceParent(lookup(traceParentKey)); traceId != "" {
+		fields[TraceIdField], fields[SpanIdField] = traceId, spanId
+	} else if traceId := lookup(b3TraceIdKey); traceId != "" {
+		fields[TraceIdField] = traceId
+		if spanId := lookup(b3SpanIdKey); spanId != "" {
+			fields[SpanIdField] = spanId
+		}
+	}
+	return &Logger{Entry: l.Entry.WithContext(ctx).WithFields(fields)}
+}
+
+// parseTraceParent reads W3C trace context header: version-traceid-spanid-flags
+func parseTraceParent(header string) (traceId, spanId string) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", ""
+	}
+	return parts[1], parts[2]
+}
@@ -0,0 +1,81 @@
+package logrus
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/reversersed/LitGO-backend-pkg/middleware"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type methodStream struct {
+	grpc.ServerTransportStream
+	method string
+}
+
+func (s methodStream) Method() string {
+	return s.method
+}
+
+func TestMetadataKeysMatchMiddleware(t *testing.T) {
+	assert.Equal(t, middleware.UserIdKey, userIdKey)
+	assert.Equal(t, middleware.UserLoginKey, userLoginKey)
+}
+func TestWithContext(t *testing.T) {
+	incoming := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		userIdKey, "66af422044f3b3de8e272a2a",
+		traceParentKey, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	))
+	outgoing := metadata.NewOutgoingContext(context.Background(), metadata.Pairs(userIdKey, "1", userLoginKey, "reader", b3TraceIdKey, "463ac35c9f6413ad", b3SpanIdKey, "a2fb4a1d1a96d312"))
+
+	table := []struct {
+		Name     string
+		Context  context.Context
+		Excepted map[string]any
+	}{
+		{"empty context", context.Background(), map[string]any{}},
+		{"incoming metadata", grpc.NewContextWithServerTransportStream(ContextWithRequestId(incoming, "request"), methodStream{method: "/books.Books/GetBook"}), map[string]any{
+			UserIdField:     "66af422044f3b3de8e272a2a",
+			GrpcMethodField: "/books.Books/GetBook",
+			TraceIdField:    "4bf92f3577b34da6a3ce929d0e0e4736",
+			SpanIdField:     "00f067aa0ba902b7",
+			RequestIdField:  "request",
+		}},
+		{"outgoing metadata", outgoing, map[string]any{
+			UserIdField:    "1",
+			UserLoginField: "reader",
+			TraceIdField:   "463ac35c9f6413ad",
+			SpanIdField:    "a2fb4a1d1a96d312",
+		}},
+	}
+	for _, v := range table {
+		t.Run(v.Name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "service.log")
+			logger, err := NewLogger(&Config{Format: FormatJSON, Outputs: []string{OutputFile}, FilePath: file})
+			assert.NoError(t, err)
+			defer logger.Close()
+
+			logger.WithContext(v.Context).Info("entry")
+
+			f, err := os.Open(file)
+			assert.NoError(t, err)
+			defer f.Close()
+			scanner := bufio.NewScanner(f)
+			assert.True(t, scanner.Scan())
+
+			var entry map[string]any
+			assert.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+			assert.Equal(t, "entry", entry["msg"])
+			assert.Equal(t, "info", entry["level"])
+			for _, field := range []string{RequestIdField, UserIdField, UserLoginField, GrpcMethodField, TraceIdField, SpanIdField} {
+				assert.Equal(t, v.Excepted[field], entry[field], field)
+			}
+		})
+	}
+}
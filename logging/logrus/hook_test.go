@@ -0,0 +1,86 @@
+package logrus
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
+
+func TestHookOutputs(t *testing.T) {
+	var text, jsonOutput, legacy bytes.Buffer
+	l := logrus.New()
+	l.SetOutput(&bytes.Buffer{})
+	l.SetLevel(logrus.TraceLevel)
+	l.Formatter = &logrus.TextFormatter{DisableColors: true, DisableTimestamp: true}
+	l.AddHook(&Hook{
+		Writer: []io.Writer{&legacy},
+		Outputs: []Output{
+			{Writer: &text, Level: logrus.DebugLevel},
+			{Writer: &jsonOutput, Level: logrus.InfoLevel, Formatter: &logrus.JSONFormatter{DisableTimestamp: true}},
+		},
+		LogLevels: logrus.AllLevels,
+	})
+
+	l.Trace("trace entry")
+	l.Debug("debug entry")
+	l.Warn("warn entry")
+
+	assert.Equal(t, "level=trace msg=\"trace entry\"\nlevel=debug msg=\"debug entry\"\nlevel=warning msg=\"warn entry\"\n", legacy.String())
+	assert.Equal(t, "level=debug msg=\"debug entry\"\nlevel=warning msg=\"warn entry\"\n", text.String())
+	assert.Equal(t, "{\"level\":\"warning\",\"msg\":\"warn entry\"}\n", jsonOutput.String())
+}
+func TestHookErrors(t *testing.T) {
+	first, second := errors.New("first writer failed"), errors.New("second writer failed")
+	var buffer bytes.Buffer
+	hook := &Hook{
+		Writer: []io.Writer{failingWriter{first}},
+		Outputs: []Output{
+			{Writer: failingWriter{second}, Level: logrus.TraceLevel},
+			{Writer: &buffer, Level: logrus.TraceLevel},
+		},
+	}
+
+	err := hook.Fire(&logrus.Entry{Logger: logrus.New(), Level: logrus.InfoLevel, Message: "entry"})
+	assert.ErrorIs(t, err, first)
+	assert.ErrorIs(t, err, second)
+	assert.Contains(t, buffer.String(), "entry")
+}
+func TestLoggerOutputLevels(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "service.log")
+	logger, err := NewLogger(&Config{Level: "warn", Outputs: []string{"file:debug:json", "stdout::text"}, FilePath: file})
+	assert.NoError(t, err)
+	assert.Equal(t, logrus.DebugLevel, logger.Logger.GetLevel())
+
+	logger.Trace("trace entry")
+	logger.Debug("debug entry")
+	assert.NoError(t, logger.Close())
+
+	content, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Len(t, lines, 1)
+	var entry map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "debug entry", entry["msg"])
+
+	for _, outputs := range [][]string{{"file:verbose"}, {"stdout:info:xml"}, {"stdout:info:json:extra"}} {
+		_, err := NewLogger(&Config{Outputs: outputs})
+		assert.Error(t, err, outputs)
+	}
+}
@@ -1,13 +1,9 @@
 package logrus
 
 import (
-	"fmt"
+	"errors"
 	"io"
-	"os"
-	"path"
-	"runtime"
 	"sync"
-	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -16,75 +12,101 @@ const (
 	dateTimeLayout = "2006-01-02 15.04.05"
 )
 
+// Hook writes entries to outputs. Writer receives every entry formatted by logger's formatter,
+// while Outputs filter entries by their own level and may use their own formatter
 type Hook struct {
 	sync.Mutex
 	Writer    []io.Writer
+	Outputs   []Output
 	LogLevels []logrus.Level
 }
 
+// Output receives entries with Level or more severe ones. Nil Formatter means logger's formatter
+type Output struct {
+	Writer    io.Writer
+	Level     logrus.Level
+	Formatter logrus.Formatter
+}
+
+// Fire writes entry to every output and returns joined errors of all failed ones
 func (hook *Hook) Fire(entry *logrus.Entry) error {
 	hook.Lock()
 	defer hook.Unlock()
 
-	line, err := entry.String()
-	if err != nil {
-		return err
+	var line []byte
+	var lineErr error
+	format := func(formatter logrus.Formatter) ([]byte, error) {
+		if formatter != nil {
+			return formatter.Format(entry)
+		}
+		if line == nil && lineErr == nil {
+			line, lineErr = entry.Bytes()
+		}
+		return line, lineErr
 	}
-	for _, w := range hook.Writer {
-		_, err = w.Write([]byte(line))
+
+	var errs []error
+	if len(hook.Writer) > 0 {
+		if data, err := format(nil); err != nil {
+			errs = append(errs, err)
+		} else {
+			for _, w := range hook.Writer {
+				if _, err := w.Write(data); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
 	}
-	if err != nil {
-		return err
+	for _, output := range hook.Outputs {
+		if entry.Level > output.Level {
+			continue
+		}
+		data, err := format(output.Formatter)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, err := output.Writer.Write(data); err != nil {
+			errs = append(errs, err)
+		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
 func (hook *Hook) Levels() []logrus.Level {
 	return hook.LogLevels
 }
 
-var e *logrus.Entry
+var logger *Logger
+var loggerErr error
 var once sync.Once
 
 type Logger struct {
 	*logrus.Entry
+	closers []io.Closer
 }
 
+// GetLogger returns shared logger writing all levels to stdout and file in logs directory. File is rotated every 100 MB,
+// ten newest rotated files are kept compressed
 func GetLogger() (*Logger, error) {
-	var er error
 	once.Do(func() {
-		l := logrus.New()
-		l.SetReportCaller(true)
-		l.Formatter = &logrus.TextFormatter{
-			CallerPrettyfier: func(f *runtime.Frame) (string, string) {
-				filename := path.Base(f.File)
-				return fmt.Sprintf("%s:%d", filename, f.Line), (f.Function + "()")
-			},
-			DisableColors: false,
-			FullTimestamp: true,
-		}
-
-		err := os.MkdirAll("logs", 0644)
-		if err != nil || os.IsExist(err) {
-			panic("can't create logs directory")
-		}
-		t := time.Now().UTC().Format(dateTimeLayout)
-		logFile, err := os.OpenFile(fmt.Sprintf("logs/%s.log", t), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0660)
-		if er = err; er != nil {
-			return
-		}
-		l.SetOutput(io.Discard)
-
-		l.AddHook(&Hook{
-			Writer:    []io.Writer{logFile, os.Stdout},
-			LogLevels: logrus.AllLevels,
+		logger, loggerErr = NewLogger(&Config{
+			Level:    logrus.TraceLevel.String(),
+			Format:   FormatText,
+			Outputs:  []string{OutputFile, OutputStdout},
+			MaxSize:  100,
+			MaxFiles: 10,
+			Compress: true,
 		})
-
-		l.SetLevel(logrus.TraceLevel)
-
-		e = logrus.NewEntry(l)
 	})
-	if er != nil {
-		return nil, er
+	return logger, loggerErr
+}
+
+// Close closes log files opened by logger
+func (l *Logger) Close() error {
+	var errs []error
+	for _, c := range l.closers {
+		errs = append(errs, c.Close())
 	}
-	return &Logger{e}, nil
+	l.closers = nil
+	return errors.Join(errs...)
 }
@@ -0,0 +1,46 @@
+package logrus
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLogger(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "nested", "service.log")
+	logger, err := NewLogger(&Config{Level: "info", Outputs: []string{"file"}, FilePath: file})
+	assert.NoError(t, err)
+
+	logger.Debug("hidden entry")
+	logger.Info("visible entry")
+	assert.NoError(t, logger.Close())
+
+	content, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "visible entry")
+	assert.NotContains(t, string(content), "hidden entry")
+}
+func TestNewLoggerErrors(t *testing.T) {
+	blocker := filepath.Join(t.TempDir(), "file")
+	assert.NoError(t, os.WriteFile(blocker, nil, 0644))
+
+	table := []struct {
+		Name   string
+		Config *Config
+	}{
+		{"nil config", nil},
+		{"invalid level", &Config{Level: "verbose"}},
+		{"invalid format", &Config{Format: "xml"}},
+		{"invalid output", &Config{Outputs: []string{"stdout", "syslog"}}},
+		{"unavailable directory", &Config{Outputs: []string{"file"}, FilePath: filepath.Join(blocker, "service.log")}},
+	}
+	for _, v := range table {
+		t.Run(v.Name, func(t *testing.T) {
+			logger, err := NewLogger(v.Config)
+			assert.Error(t, err)
+			assert.Nil(t, logger)
+		})
+	}
+}
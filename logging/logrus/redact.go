@@ -0,0 +1,181 @@
+package logrus
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const RedactedValue = "[REDACTED]"
+
+// DefaultRedactedFields are masked by NewLogger unless redaction is disabled. Cookie names used by middleware
+// contain "token", so their values are masked too
+var DefaultRedactedFields = []string{"password", "passwd", "secret", "token", "authorization", "cookie", "apikey", "api_key"}
+
+var (
+	jwtPattern          = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	emailPattern        = regexp.MustCompile(`\b([A-Za-z0-9._%+-])[A-Za-z0-9._%+-]*@([A-Za-z0-9.-]+\.[A-Za-z]{2,})\b`)
+	cookieHeaderPattern = regexp.MustCompile(`(?i)(\b(?:set-)?cookie\s*:\s*)[^\r\n]+`)
+)
+
+// Redactor is a hook masking sensitive data in entries. It must be added to logger before hooks writing entries.
+// Values of fields which names contain any of configured names are masked entirely, as StructValidation does with passwords,
+// including keys and fields of nested maps and structs, e.g. metadata.MD. Messages and strings are searched for JWTs,
+// emails, cookie headers and name=value pairs of configured names
+type Redactor struct {
+	fields       []string
+	valuePattern *regexp.Regexp
+}
+
+func NewRedactor(fields ...string) *Redactor {
+	r := &Redactor{}
+	quoted := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field = strings.ToLower(strings.TrimSpace(field)); field != "" {
+			r.fields = append(r.fields, field)
+			quoted = append(quoted, regexp.QuoteMeta(field))
+		}
+	}
+	if len(quoted) > 0 {
+		r.valuePattern = regexp.MustCompile(fmt.Sprintf(`(?i)(\b\w*(?:%s)\w*["']?\s*[:=]\s*\[?["']?)([^\s"',;\]}]+)`, strings.Join(quoted, "|")))
+	}
+	return r
+}
+func (r *Redactor) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+func (r *Redactor) Fire(entry *logrus.Entry) error {
+	entry.Message = r.Redact(entry.Message)
+	for key, value := range entry.Data {
+		if r.sensitive(key) {
+			entry.Data[key] = RedactedValue
+			continue
+		}
+		if redacted, ok := r.redactValue(reflect.ValueOf(value), 0); ok {
+			entry.Data[key] = redacted
+		}
+	}
+	return nil
+}
+
+// maxRedactDepth limits walking through nested values, so cyclic ones don't hang the logger
+const maxRedactDepth = 16
+
+// redactValue returns redacted copy of value and true if anything was redacted. Caller's value is never changed.
+// Maps and structs are copied into map[string]any keyed as json encodes them, slices into []any or []string.
+// Errors and fmt.Stringer values are redacted as text
+func (r *Redactor) redactValue(v reflect.Value, depth int) (any, bool) {
+	if !v.IsValid() || depth > maxRedactDepth {
+		return nil, false
+	}
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil, false
+	}
+	if v.CanInterface() {
+		switch value := v.Interface().(type) {
+		case error:
+			text := value.Error()
+			redacted := r.Redact(text)
+			return redacted, redacted != text
+		case fmt.Stringer:
+			text := value.String()
+			redacted := r.Redact(text)
+			return redacted, redacted != text
+		}
+	}
+	switch v.Kind() {
+	case reflect.String:
+		redacted := r.Redact(v.String())
+		return redacted, redacted != v.String()
+	case reflect.Pointer, reflect.Interface:
+		return r.redactValue(v.Elem(), depth+1)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return nil, false
+		}
+		if v.Type().Elem().Kind() == reflect.String {
+			redacted, changed := make([]string, v.Len()), false
+			for i := range redacted {
+				redacted[i] = r.Redact(v.Index(i).String())
+				changed = changed || redacted[i] != v.Index(i).String()
+			}
+			return redacted, changed
+		}
+		redacted, changed := make([]any, v.Len()), false
+		for i := range redacted {
+			redacted[i] = r.redactItem(v.Index(i), depth, &changed)
+		}
+		return redacted, changed
+	case reflect.Map:
+		redacted, changed := make(map[string]any, v.Len()), false
+		iter := v.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			if r.sensitive(key) {
+				redacted[key], changed = RedactedValue, true
+				continue
+			}
+			redacted[key] = r.redactItem(iter.Value(), depth, &changed)
+		}
+		return redacted, changed
+	case reflect.Struct:
+		redacted, changed := make(map[string]any, v.NumField()), false
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if !field.IsExported() || name == "-" {
+				// such fields aren't in the copy, so the copy is used if they may hold sensitive data
+				changed = changed || r.sensitive(field.Name)
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			if r.sensitive(name) || r.sensitive(field.Name) {
+				redacted[name], changed = RedactedValue, true
+				continue
+			}
+			redacted[name] = r.redactItem(v.Field(i), depth, &changed)
+		}
+		return redacted, changed
+	}
+	return nil, false
+}
+
+// redactItem returns redacted copy of nested item or the item itself if nothing was redacted
+func (r *Redactor) redactItem(v reflect.Value, depth int, changed *bool) any {
+	if redacted, ok := r.redactValue(v, depth+1); ok {
+		*changed = true
+		return redacted
+	}
+	if !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// Redact masks sensitive values in text
+func (r *Redactor) Redact(text string) string {
+	if text == "" {
+		return text
+	}
+	if r.valuePattern != nil {
+		text = r.valuePattern.ReplaceAllString(text, "${1}"+RedactedValue)
+	}
+	text = cookieHeaderPattern.ReplaceAllString(text, "${1}"+RedactedValue)
+	text = jwtPattern.ReplaceAllString(text, RedactedValue)
+	return emailPattern.ReplaceAllString(text, "${1}***@${2}")
+}
+func (r *Redactor) sensitive(field string) bool {
+	field = strings.ToLower(field)
+	for _, name := range r.fields {
+		if strings.Contains(field, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,283 @@
+package logrus
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reversersed/LitGO-backend-pkg/shutdown"
+)
+
+const rotatedTimeLayout = "2006-01-02 15.04.05.000"
+
+// RotationPolicy configures RotatingFile. Zero values disable corresponding rule
+type RotationPolicy struct {
+	MaxSize  int64         // rotate when file grows over MaxSize bytes
+	Interval time.Duration // rotate when file is older than Interval
+	MaxAge   time.Duration // remove rotated files older than MaxAge
+	MaxFiles int           // keep only MaxFiles newest rotated files
+	Compress bool          // gzip rotated files
+}
+
+// RotatingFile writes to file at path, renaming it to path with timestamp suffix when policy limits are reached
+type RotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	policy   RotationPolicy
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	now      func() time.Time
+
+	// rotated decides which files in directory belong to this logger
+	rotated       func(name string) bool
+	background    sync.WaitGroup
+	cleanup       sync.Mutex // serializes compression and removal of rotated files
+	signals       chan os.Signal
+	reopenSignals []os.Signal
+}
+
+// NewRotatingFile opens file at path. Retention is applied to rotated files left by previous runs right away
+func NewRotatingFile(path string, policy RotationPolicy) (*RotatingFile, error) {
+	return newRotatingFile(path, policy, rotatedNames(path))
+}
+func newRotatingFile(path string, policy RotationPolicy, rotated func(name string) bool) (*RotatingFile, error) {
+	f := &RotatingFile{path: path, policy: policy, now: time.Now, rotated: rotated}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("can't create logs directory: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	f.cleanupInBackground("")
+	return f, nil
+}
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0660)
+	if err != nil {
+		return fmt.Errorf("can't open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("can't open log file: %w", err)
+	}
+	f.file, f.size, f.openedAt = file, info.Size(), f.now()
+	return nil
+}
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.size > 0 && (f.policy.MaxSize > 0 && f.size+int64(len(p)) > f.policy.MaxSize || f.policy.Interval > 0 && f.now().Sub(f.openedAt) >= f.policy.Interval) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate renames current file and starts new one regardless of policy limits
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	return f.rotate()
+}
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	ext := filepath.Ext(f.path)
+	rotated := fmt.Sprintf("%s-%s%s", strings.TrimSuffix(f.path, ext), f.now().UTC().Format(rotatedTimeLayout), ext)
+	for i := 1; fileExists(rotated) || fileExists(rotated+".gz"); i++ {
+		rotated = fmt.Sprintf("%s-%s.%d%s", strings.TrimSuffix(f.path, ext), f.now().UTC().Format(rotatedTimeLayout), i, ext)
+	}
+	if err := os.Rename(f.path, rotated); err != nil {
+		return errors.Join(err, f.open())
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.cleanupInBackground(rotated)
+	return nil
+}
+
+// cleanupInBackground compresses just rotated file, if any, and applies retention
+func (f *RotatingFile) cleanupInBackground(rotated string) {
+	f.background.Add(1)
+	go func() {
+		defer f.background.Done()
+		f.cleanup.Lock()
+		defer f.cleanup.Unlock()
+
+		if rotated != "" && f.policy.Compress {
+			// file stays uncompressed on error and is removed by retention as usual
+			_ = compressFile(rotated)
+		}
+		f.removeExpired()
+	}()
+}
+
+// Reopen closes and opens file at the same path, e.g. after it was moved by external tool
+func (f *RotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	return f.open()
+}
+
+// ReopenOn reopens file on every received signal until file is closed.
+// Signals are reserved meanwhile, so shutdown.Graceful doesn't stop the service on them
+func (f *RotatingFile) ReopenOn(signals ...os.Signal) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.signals != nil || f.file == nil {
+		return
+	}
+	f.signals = make(chan os.Signal, 1)
+	f.reopenSignals = signals
+	shutdown.Reserve(signals...)
+	signal.Notify(f.signals, signals...)
+	go func(ch chan os.Signal) {
+		for range ch {
+			// write errors will be reported by hook if file can't be reopened
+			_ = f.Reopen()
+		}
+	}(f.signals)
+}
+
+// Close closes file and waits for compression and cleanup of rotated files
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	if f.signals != nil {
+		signal.Stop(f.signals)
+		close(f.signals)
+		shutdown.Release(f.reopenSignals...)
+		f.signals, f.reopenSignals = nil, nil
+	}
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.mu.Unlock()
+
+	f.background.Wait()
+	return err
+}
+
+// removeExpired removes rotated files exceeding MaxFiles or older than MaxAge
+func (f *RotatingFile) removeExpired() {
+	if f.policy.MaxAge <= 0 && f.policy.MaxFiles <= 0 {
+		return
+	}
+
+	dir := filepath.Dir(f.path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	type rotatedFile struct {
+		path    string
+		modTime time.Time
+	}
+	var files []rotatedFile
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || entry.Name() == filepath.Base(f.path) || !f.rotated(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, rotatedFile{path: filepath.Join(dir, entry.Name()), modTime: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+	for i, file := range files {
+		if f.policy.MaxFiles > 0 && i >= f.policy.MaxFiles || f.policy.MaxAge > 0 && f.now().Sub(file.modTime) > f.policy.MaxAge {
+			os.Remove(file.path)
+		}
+	}
+}
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	gz := gzip.NewWriter(tmp)
+	if _, err := io.Copy(gz, src); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := errors.Join(gz.Close(), tmp.Close()); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path+".gz"); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// rotatedNames matches names given to rotated files of path: base name, timestamp,
+// optional sequence number if timestamp is taken, extension and optional .gz
+func rotatedNames(path string) func(name string) bool {
+	ext := filepath.Ext(path)
+	prefix := strings.TrimSuffix(filepath.Base(path), ext) + "-"
+	return func(name string) bool {
+		name = strings.TrimSuffix(name, ".gz")
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			return false
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		if len(stamp) < len(rotatedTimeLayout) {
+			return false
+		}
+		if _, err := time.Parse(rotatedTimeLayout, stamp[:len(rotatedTimeLayout)]); err != nil {
+			return false
+		}
+		if sequence := stamp[len(rotatedTimeLayout):]; sequence != "" {
+			n, err := strconv.Atoi(strings.TrimPrefix(sequence, "."))
+			return strings.HasPrefix(sequence, ".") && err == nil && n > 0
+		}
+		return true
+	}
+}
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,186 @@
+package logrus
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func rotatedFiles(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		if entry.Name() != "service.log" {
+			names = append(names, entry.Name())
+		}
+	}
+	return names
+}
+func TestRotateBySize(t *testing.T) {
+	dir := t.TempDir()
+	file, err := NewRotatingFile(filepath.Join(dir, "service.log"), RotationPolicy{MaxSize: 100})
+	assert.NoError(t, err)
+
+	line := strings.Repeat("a", 59) + "\n"
+	for i := 0; i < 3; i++ {
+		_, err := file.Write([]byte(line))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, file.Close())
+
+	rotated := rotatedFiles(t, dir)
+	assert.Len(t, rotated, 2)
+	for _, name := range rotated {
+		assert.True(t, strings.HasPrefix(name, "service-") && strings.HasSuffix(name, ".log"), name)
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		assert.Equal(t, line, string(content))
+	}
+
+	_, err = file.Write([]byte(line))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+func TestRotateByInterval(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	file, err := NewRotatingFile(filepath.Join(dir, "service.log"), RotationPolicy{Interval: time.Hour})
+	assert.NoError(t, err)
+	file.now = func() time.Time { return now }
+	assert.NoError(t, file.Reopen())
+
+	_, err = file.Write([]byte("first\n"))
+	assert.NoError(t, err)
+	now = now.Add(30 * time.Minute)
+	_, err = file.Write([]byte("second\n"))
+	assert.NoError(t, err)
+	now = now.Add(30 * time.Minute)
+	_, err = file.Write([]byte("third\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	assert.Equal(t, []string{"service-2024-05-01 13.00.00.000.log"}, rotatedFiles(t, dir))
+	content, err := os.ReadFile(filepath.Join(dir, "service.log"))
+	assert.NoError(t, err)
+	assert.Equal(t, "third\n", string(content))
+}
+func TestRotationRetention(t *testing.T) {
+	dir := t.TempDir()
+	file, err := NewRotatingFile(filepath.Join(dir, "service.log"), RotationPolicy{MaxFiles: 2, MaxAge: 24 * time.Hour, Compress: true})
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "other.log"), nil, 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "service-api.log"), nil, 0644))
+	expired := filepath.Join(dir, "service-2024-05-01 12.00.00.000.log.gz")
+	assert.NoError(t, os.WriteFile(expired, nil, 0644))
+	assert.NoError(t, os.Chtimes(expired, time.Now().Add(-48*time.Hour), time.Now().Add(-48*time.Hour)))
+
+	for i := 0; i < 4; i++ {
+		_, err := file.Write([]byte("entry\n"))
+		assert.NoError(t, err)
+		assert.NoError(t, file.Rotate())
+	}
+	assert.NoError(t, file.Close())
+
+	rotated := rotatedFiles(t, dir)
+	assert.Len(t, rotated, 4)
+	assert.Contains(t, rotated, "other.log")
+	assert.Contains(t, rotated, "service-api.log")
+	for _, name := range rotated {
+		if name == "other.log" || name == "service-api.log" {
+			continue
+		}
+		assert.True(t, strings.HasSuffix(name, ".log.gz"), name)
+		f, err := os.Open(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		gz, err := gzip.NewReader(f)
+		assert.NoError(t, err)
+		content, err := io.ReadAll(gz)
+		assert.NoError(t, err)
+		assert.Equal(t, "entry\n", string(content))
+		f.Close()
+	}
+}
+func TestRotatedNames(t *testing.T) {
+	rotated := rotatedNames(filepath.Join("logs", "service.log"))
+	table := []struct {
+		Name     string
+		Excepted bool
+	}{
+		{"service-2024-05-01 12.00.00.000.log", true},
+		{"service-2024-05-01 12.00.00.000.log.gz", true},
+		{"service-2024-05-01 12.00.00.000.2.log", true},
+		{"service.log", false},
+		{"service-api.log", false},
+		{"service-api-2024-05-01 12.00.00.000.log", false},
+		{"service-2024-05-01 12.00.00.000.x.log", false},
+		{"2024-05-01 12.00.00-2024-05-01 12.00.00.000.log", false},
+	}
+	for _, v := range table {
+		assert.Equal(t, v.Excepted, rotated(v.Name), v.Name)
+	}
+}
+func TestStartTimeRetention(t *testing.T) {
+	dir := t.TempDir()
+	previous := []string{
+		"2024-05-01 10.00.00.log",
+		"2024-05-01 10.00.00-2024-05-01 11.00.00.000.log.gz",
+		"2024-05-01 12.00.00.log",
+		"2024-05-01 13.00.00.log",
+	}
+	for i, name := range previous {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, nil, 0644))
+		modTime := time.Now().Add(time.Duration(i-len(previous)) * time.Hour)
+		assert.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "other.log"), nil, 0644))
+
+	file, err := newRotatingFile(filepath.Join(dir, "2024-05-02 10.00.00.log"), RotationPolicy{MaxFiles: 2}, startTimeNames)
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	assert.ElementsMatch(t, []string{"2024-05-01 12.00.00.log", "2024-05-01 13.00.00.log", "2024-05-02 10.00.00.log", "other.log"}, rotatedFiles(t, dir))
+}
+func TestStartTimeNames(t *testing.T) {
+	table := []struct {
+		Name     string
+		Excepted bool
+	}{
+		{"2024-05-01 12.00.00.log", true},
+		{"2024-05-01 12.00.00-2024-05-01 13.00.00.000.log", true},
+		{"2024-05-01 12.00.00-2024-05-01 13.00.00.000.1.log.gz", true},
+		{"2024-05-01 12.00.00.txt", false},
+		{"2024-05-01 12.00.00-api.log", false},
+		{"service.log", false},
+	}
+	for _, v := range table {
+		assert.Equal(t, v.Excepted, startTimeNames(v.Name), v.Name)
+	}
+}
+func TestReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "service.log")
+	file, err := NewRotatingFile(path, RotationPolicy{})
+	assert.NoError(t, err)
+	defer file.Close()
+
+	_, err = file.Write([]byte("before\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, os.Rename(path, filepath.Join(dir, "moved.log")))
+	assert.NoError(t, file.Reopen())
+	_, err = file.Write([]byte("after\n"))
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "after\n", string(content))
+	content, err = os.ReadFile(filepath.Join(dir, "moved.log"))
+	assert.NoError(t, err)
+	assert.Equal(t, "before\n", string(content))
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/cristalhq/jwt/v3"
 	"github.com/gin-gonic/gin"
+	golangmock "github.com/golang/mock/gomock"
 	mock_middleware "github.com/reversersed/LitGO-backend-pkg/middleware/mocks"
 	users_pb "github.com/reversersed/LitGO-proto/gen/go/users"
 	users_mock_pb "github.com/reversersed/LitGO-proto/gen/go/users/mocks"
@@ -214,9 +215,12 @@ func TestMiddleware(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
+			// users mocks are generated by github.com/golang/mock, so they need its own controller
+			usersCtrl := golangmock.NewController(t)
+			defer usersCtrl.Finish()
 
 			logger := mock_middleware.NewMockLogger(ctrl)
-			server := users_mock_pb.NewMockUserClient(ctrl)
+			server := users_mock_pb.NewMockUserClient(usersCtrl)
 
 			if tt.mockBehaviour != nil {
 				tt.mockBehaviour(logger, server)
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	cache "github.com/reversersed/LitGO-backend-pkg/cache"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+type KeyFunc func(*gin.Context) string
+
+func KeyByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// KeyByUser limits authorized users by id from jwt middleware, so it must be used after it.
+// Anonymous requests are limited by ip
+func KeyByUser(c *gin.Context) string {
+	if md, ok := metadata.FromOutgoingContext(c.Request.Context()); ok {
+		if id := md.Get(UserIdKey); len(id) == 1 {
+			return "user:" + id[0]
+		}
+	}
+	return KeyByIP(c)
+}
+
+type rateLimitMiddleware struct {
+	logger  Logger
+	limiter cache.RateLimiter
+	key     KeyFunc
+}
+
+// NewRateLimitMiddleware creates middleware limiting requests by key. Logger can be nil, then limiter errors aren't logged
+func NewRateLimitMiddleware(logger Logger, limiter cache.RateLimiter, key KeyFunc) *rateLimitMiddleware {
+	return &rateLimitMiddleware{
+		logger:  logger,
+		limiter: limiter,
+		key:     key,
+	}
+}
+
+// Middleware rejects requests over the limit with 429 status and Retry-After header.
+// Requests are let through if limiter's store is unavailable
+func (r *rateLimitMiddleware) Middleware(c *gin.Context) {
+	key := r.key(c)
+	allowed, retryAfter, err := r.limiter.Allow(key)
+	if err != nil {
+		if r.logger != nil {
+			r.logger.Errorf("error checking rate limit for %s: %v", key, err)
+		}
+		c.Next()
+		return
+	}
+	if !allowed {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		c.Error(rateLimitError(retryAfter))
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+// NewRateLimitInterceptor limits calls by user id from incoming metadata or by peer's ip.
+// Logger can be nil, then limiter errors aren't logged
+func NewRateLimitInterceptor(logger Logger, limiter cache.RateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		key := grpcRateLimitKey(ctx)
+		allowed, retryAfter, err := limiter.Allow(key)
+		if err != nil {
+			if logger != nil {
+				logger.Errorf("error checking rate limit for %s: %v", key, err)
+			}
+			return handler(ctx, req)
+		}
+		if !allowed {
+			return nil, rateLimitError(retryAfter)
+		}
+		return handler(ctx, req)
+	}
+}
+func grpcRateLimitKey(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if id := md.Get(UserIdKey); len(id) == 1 {
+			return "user:" + id[0]
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		return "ip:" + host
+	}
+	return "ip:unknown"
+}
+func rateLimitError(retryAfter time.Duration) error {
+	stat, err := status.New(codes.ResourceExhausted, "too many requests, try again later").WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(retryAfter),
+	})
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, "too many requests, try again later")
+	}
+	return stat.Err()
+}
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	mock_middleware "github.com/reversersed/LitGO-backend-pkg/middleware/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type stubLimiter struct {
+	keys       []string
+	allowed    bool
+	retryAfter time.Duration
+	err        error
+}
+
+func (s *stubLimiter) Allow(key string) (bool, time.Duration, error) {
+	s.keys = append(s.keys, key)
+	return s.allowed, s.retryAfter, s.err
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	table := []struct {
+		Name           string
+		Limiter        *stubLimiter
+		Key            KeyFunc
+		Request        func() *http.Request
+		MockBehaviour  func(*mock_middleware.MockLogger)
+		ExceptedStatus int
+		ExceptedKey    string
+		ExceptedRetry  string
+	}{
+		{
+			Name:           "allowed by ip",
+			Limiter:        &stubLimiter{allowed: true},
+			Key:            KeyByIP,
+			ExceptedStatus: http.StatusOK,
+			ExceptedKey:    "ip:192.0.2.1",
+		},
+		{
+			Name:           "rejected",
+			Limiter:        &stubLimiter{retryAfter: 1500 * time.Millisecond},
+			Key:            KeyByIP,
+			ExceptedStatus: http.StatusTooManyRequests,
+			ExceptedKey:    "ip:192.0.2.1",
+			ExceptedRetry:  "2",
+		},
+		{
+			Name:    "limiter error lets request through",
+			Limiter: &stubLimiter{err: errors.New("store is down")},
+			Key:     KeyByIP,
+			MockBehaviour: func(logger *mock_middleware.MockLogger) {
+				logger.EXPECT().Errorf(gomock.Any(), gomock.Any(), gomock.Any())
+			},
+			ExceptedStatus: http.StatusOK,
+			ExceptedKey:    "ip:192.0.2.1",
+		},
+		{
+			Name:    "keyed by user",
+			Limiter: &stubLimiter{allowed: true},
+			Key:     KeyByUser,
+			Request: func() *http.Request {
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				ctx := metadata.NewOutgoingContext(r.Context(), metadata.Pairs(UserIdKey, "userid"))
+				return r.WithContext(ctx)
+			},
+			ExceptedStatus: http.StatusOK,
+			ExceptedKey:    "user:userid",
+		},
+		{
+			Name:           "anonymous user keyed by ip",
+			Limiter:        &stubLimiter{allowed: true},
+			Key:            KeyByUser,
+			ExceptedStatus: http.StatusOK,
+			ExceptedKey:    "ip:192.0.2.1",
+		},
+	}
+	gin.SetMode(gin.ReleaseMode)
+	for _, v := range table {
+		t.Run(v.Name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			logger := mock_middleware.NewMockLogger(ctrl)
+			if v.MockBehaviour != nil {
+				v.MockBehaviour(logger)
+			}
+
+			router := gin.New()
+			router.Use(ErrorHandler)
+			router.Use(NewRateLimitMiddleware(logger, v.Limiter, v.Key).Middleware)
+			router.GET("/", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if v.Request != nil {
+				r = v.Request()
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, v.ExceptedStatus, w.Code)
+			assert.Equal(t, []string{v.ExceptedKey}, v.Limiter.keys)
+			assert.Equal(t, v.ExceptedRetry, w.Header().Get("Retry-After"))
+		})
+	}
+}
+func TestRateLimitInterceptor(t *testing.T) {
+	handler := func(ctx context.Context, req any) (any, error) {
+		return "reply", nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/books.Books/GetBook"}
+
+	limiter := &stubLimiter{allowed: true}
+	interceptor := NewRateLimitInterceptor(nil, limiter)
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 5000}})
+	reply, err := interceptor(ctx, nil, info, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "reply", reply)
+
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(UserIdKey, "userid"))
+	limiter.allowed, limiter.retryAfter = false, 3*time.Second
+	_, err = interceptor(ctx, nil, info, handler)
+	assert.Equal(t, []string{"ip:192.0.2.1", "user:userid"}, limiter.keys)
+
+	stat, ok := status.FromError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, codes.ResourceExhausted, stat.Code())
+		if assert.Len(t, stat.Details(), 1) {
+			info, ok := stat.Details()[0].(*errdetails.RetryInfo)
+			if assert.True(t, ok) {
+				assert.Equal(t, 3*time.Second, info.GetRetryDelay().AsDuration())
+			}
+		}
+	}
+
+	limiter.err = errors.New("store is down")
+	reply, err = interceptor(ctx, nil, info, handler)
+	assert.NoError(t, err, "limiter error lets call through without logger")
+	assert.Equal(t, "reply", reply)
+}
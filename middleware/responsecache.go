@@ -0,0 +1,219 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	cache "github.com/reversersed/LitGO-backend-pkg/cache"
+)
+
+const responseCacheKeyPrefix = "response:"
+
+type cachedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+type responseCache struct {
+	store       cache.Cache
+	ttl         int
+	varyHeaders []string
+}
+
+// NewResponseCache caches successful GET and HEAD responses for anonymous users.
+// Responses are stored for ttl seconds, unless handler sets Cache-Control max-age.
+// Values of varyHeaders become part of cache key, e.g. Accept-Language
+func NewResponseCache(store cache.Cache, ttl int, varyHeaders ...string) *responseCache {
+	return &responseCache{
+		store:       store,
+		ttl:         ttl,
+		varyHeaders: varyHeaders,
+	}
+}
+
+func (r *responseCache) Middleware(c *gin.Context) {
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		c.Next()
+		return
+	}
+	if _, err := c.Cookie(TokenCookieName); err == nil {
+		c.Next()
+		return
+	}
+	requestControl := parseCacheControl(c.GetHeader("Cache-Control"))
+	if _, noStore := requestControl["no-store"]; noStore {
+		c.Next()
+		return
+	}
+	key := r.key(c.Request)
+
+	_, noCache := requestControl["no-cache"]
+	if maxAge, ok := requestControl["max-age"]; ok && maxAge == "0" {
+		noCache = true
+	}
+	if !noCache {
+		if data, err := r.store.Get(key); err == nil {
+			var response cachedResponse
+			if err := json.Unmarshal(data, &response); err == nil {
+				c.Header("X-Cache", "HIT")
+				r.respond(c, &response)
+				c.Abort()
+				return
+			}
+		}
+	}
+
+	original := c.Writer
+	writer := &bufferedWriter{ResponseWriter: original, status: http.StatusOK}
+	c.Writer = writer
+	c.Next()
+	c.Writer = original
+
+	if len(c.Errors) > 0 {
+		if writer.written {
+			c.Status(writer.status)
+			_, _ = original.Write(writer.body.Bytes())
+		}
+		return
+	}
+	response := &cachedResponse{
+		Status: writer.status,
+		Header: original.Header().Clone(),
+		Body:   writer.body.Bytes(),
+	}
+	if response.Header.Get("ETag") == "" {
+		sum := sha256.Sum256(response.Body)
+		response.Header.Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	}
+	if ttl, ok := r.storeTTL(response); ok {
+		response.Header.Del("X-Cache")
+		if data, err := json.Marshal(response); err == nil {
+			_ = r.store.Set(key, data, ttl)
+		}
+	}
+	c.Header("X-Cache", "MISS")
+	r.respond(c, response)
+}
+func (r *responseCache) respond(c *gin.Context, response *cachedResponse) {
+	header := c.Writer.Header()
+	for name, values := range response.Header {
+		header[name] = values
+	}
+	etag := response.Header.Get("ETag")
+	if response.Status == http.StatusOK && etag != "" && etagMatches(c.GetHeader("If-None-Match"), etag) {
+		header.Del("Content-Length")
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	c.Status(response.Status)
+	if c.Request.Method == http.MethodHead {
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	_, _ = c.Writer.Write(response.Body)
+}
+
+// storeTTL reports whether response can be cached and for how long
+func (r *responseCache) storeTTL(response *cachedResponse) (int, bool) {
+	if response.Status != http.StatusOK || len(response.Header.Values("Set-Cookie")) > 0 {
+		return 0, false
+	}
+	control := parseCacheControl(response.Header.Get("Cache-Control"))
+	for _, directive := range []string{"no-store", "no-cache", "private"} {
+		if _, ok := control[directive]; ok {
+			return 0, false
+		}
+	}
+	for _, directive := range []string{"s-maxage", "max-age"} {
+		if value, ok := control[directive]; ok {
+			ttl, err := strconv.Atoi(value)
+			if err != nil || ttl <= 0 {
+				return 0, false
+			}
+			return ttl, true
+		}
+	}
+	return r.ttl, true
+}
+func (r *responseCache) key(request *http.Request) []byte {
+	var key strings.Builder
+	key.WriteString(responseCacheKeyPrefix)
+	key.WriteString(request.Method)
+	key.WriteByte(' ')
+	key.WriteString(request.URL.Path)
+	key.WriteByte('?')
+	key.WriteString(request.URL.Query().Encode())
+	for _, name := range r.varyHeaders {
+		key.WriteByte('\n')
+		key.WriteString(http.CanonicalHeaderKey(name))
+		key.WriteByte(':')
+		key.WriteString(strings.Join(request.Header.Values(name), ","))
+	}
+	return []byte(key.String())
+}
+func parseCacheControl(header string) map[string]string {
+	directives := make(map[string]string)
+	for _, part := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name == "" {
+			continue
+		}
+		directives[strings.ToLower(name)] = strings.Trim(value, `"`)
+	}
+	return directives
+}
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedWriter holds response in memory until handlers chain is done
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.written = true
+}
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+func (w *bufferedWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+func (w *bufferedWriter) Written() bool {
+	return w.written
+}
+func (w *bufferedWriter) Flush() {}
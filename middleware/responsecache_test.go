@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	cache "github.com/reversersed/LitGO-backend-pkg/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCachedRouter(calls *int, handler func(*gin.Context), varyHeaders ...string) *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(ErrorHandler)
+	router.Use(NewResponseCache(cache.NewFreeCache(0), 60, varyHeaders...).Middleware)
+	router.Any("/books", func(c *gin.Context) {
+		*calls++
+		handler(c)
+	})
+	return router
+}
+func serve(router *gin.Engine, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	return w
+}
+func TestResponseCache(t *testing.T) {
+	calls := 0
+	router := newCachedRouter(&calls, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"page": c.Query("page")})
+	})
+
+	first := serve(router, httptest.NewRequest(http.MethodGet, "/books?page=1&sort=name", nil))
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "MISS", first.Header().Get("X-Cache"))
+	assert.NotEmpty(t, first.Header().Get("ETag"))
+
+	second := serve(router, httptest.NewRequest(http.MethodGet, "/books?sort=name&page=1", nil))
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, "HIT", second.Header().Get("X-Cache"))
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, first.Header().Get("ETag"), second.Header().Get("ETag"))
+	assert.Equal(t, "application/json; charset=utf-8", second.Header().Get("Content-Type"))
+	assert.Equal(t, 1, calls)
+
+	r := httptest.NewRequest(http.MethodGet, "/books?page=1&sort=name", nil)
+	r.Header.Set("If-None-Match", first.Header().Get("ETag"))
+	notModified := serve(router, r)
+	assert.Equal(t, http.StatusNotModified, notModified.Code)
+	assert.Empty(t, notModified.Body.String())
+
+	serve(router, httptest.NewRequest(http.MethodGet, "/books?page=2", nil))
+	assert.Equal(t, 2, calls)
+}
+func TestResponseCacheBypass(t *testing.T) {
+	table := []struct {
+		Name          string
+		Handler       func(*gin.Context)
+		Request       func() *http.Request
+		VaryHeaders   []string
+		ExceptedCalls int
+	}{
+		{
+			Name:    "authorized user",
+			Handler: func(c *gin.Context) { c.String(http.StatusOK, "user page") },
+			Request: func() *http.Request {
+				r := httptest.NewRequest(http.MethodGet, "/books", nil)
+				r.AddCookie(&http.Cookie{Name: TokenCookieName, Value: "token"})
+				return r
+			},
+			ExceptedCalls: 2,
+		},
+		{
+			Name:    "not a get request",
+			Handler: func(c *gin.Context) { c.Status(http.StatusOK) },
+			Request: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/books", nil)
+			},
+			ExceptedCalls: 2,
+		},
+		{
+			Name:    "request no-store",
+			Handler: func(c *gin.Context) { c.String(http.StatusOK, "page") },
+			Request: func() *http.Request {
+				r := httptest.NewRequest(http.MethodGet, "/books", nil)
+				r.Header.Set("Cache-Control", "no-store")
+				return r
+			},
+			ExceptedCalls: 2,
+		},
+		{
+			Name:    "request no-cache",
+			Handler: func(c *gin.Context) { c.String(http.StatusOK, "page") },
+			Request: func() *http.Request {
+				r := httptest.NewRequest(http.MethodGet, "/books", nil)
+				r.Header.Set("Cache-Control", "max-age=0")
+				return r
+			},
+			ExceptedCalls: 2,
+		},
+		{
+			Name: "response private",
+			Handler: func(c *gin.Context) {
+				c.Header("Cache-Control", "private, max-age=60")
+				c.String(http.StatusOK, "page")
+			},
+			Request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/books", nil)
+			},
+			ExceptedCalls: 2,
+		},
+		{
+			Name: "response sets cookie",
+			Handler: func(c *gin.Context) {
+				c.SetCookie("visited", "1", 60, "/", "", true, true)
+				c.String(http.StatusOK, "page")
+			},
+			Request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/books", nil)
+			},
+			ExceptedCalls: 2,
+		},
+		{
+			Name:    "error response",
+			Handler: func(c *gin.Context) { c.Error(errors.New("database is down")) },
+			Request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/books", nil)
+			},
+			ExceptedCalls: 2,
+		},
+		{
+			Name:        "vary header differs",
+			Handler:     func(c *gin.Context) { c.String(http.StatusOK, c.GetHeader("Accept-Language")) },
+			VaryHeaders: []string{"Accept-Language"},
+			Request: func() func() *http.Request {
+				languages := []string{"ru", "en"}
+				return func() *http.Request {
+					r := httptest.NewRequest(http.MethodGet, "/books", nil)
+					r.Header.Set("Accept-Language", languages[0])
+					languages = languages[1:]
+					return r
+				}
+			}(),
+			ExceptedCalls: 2,
+		},
+		{
+			Name: "response max-age is used",
+			Handler: func(c *gin.Context) {
+				c.Header("Cache-Control", "public, max-age=30")
+				c.String(http.StatusOK, "page")
+			},
+			Request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/books", nil)
+			},
+			ExceptedCalls: 1,
+		},
+	}
+	for _, v := range table {
+		t.Run(v.Name, func(t *testing.T) {
+			calls := 0
+			router := newCachedRouter(&calls, v.Handler, v.VaryHeaders...)
+			first := serve(router, v.Request())
+			second := serve(router, v.Request())
+
+			assert.Equal(t, v.ExceptedCalls, calls)
+			assert.Equal(t, first.Code, second.Code)
+		})
+	}
+}
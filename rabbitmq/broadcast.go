@@ -0,0 +1,154 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	reopenDelay    = 100 * time.Millisecond
+	maxReopenDelay = 5 * time.Second
+)
+
+// broadcaster sends messages to every subscribed replica through fanout exchange.
+// Each subscriber gets its own exclusive queue which is removed when connection closes.
+// If channel is closed by broker, broadcaster opens a new one and subscribes handlers again.
+// Messages published meanwhile are lost for them
+type broadcaster struct {
+	sync.Mutex
+	client   *rabbitClient
+	channel  *amqp.Channel
+	exchange string
+	handlers []func(message []byte)
+	closed   bool
+	err      error
+}
+
+func (c *rabbitClient) NewBroadcaster(exchange string) (*broadcaster, error) {
+	b := &broadcaster{client: c, exchange: exchange}
+	if err := b.open(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Err returns reason broadcaster stopped delivering messages, e.g. closed connection.
+// It returns nil while broadcaster works or reopens its channel
+func (b *broadcaster) Err() error {
+	b.Lock()
+	defer b.Unlock()
+	return b.err
+}
+func (b *broadcaster) Publish(message []byte) error {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.err != nil {
+		return b.err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return b.channel.PublishWithContext(ctx, b.exchange, "", false, false, amqp.Publishing{
+		ContentType: "application/octet-stream",
+		Body:        message,
+	})
+}
+func (b *broadcaster) Subscribe(handler func(message []byte)) error {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.err != nil {
+		return b.err
+	}
+	if err := b.consume(b.channel, handler); err != nil {
+		return err
+	}
+	b.handlers = append(b.handlers, handler)
+	return nil
+}
+func (b *broadcaster) Close() error {
+	b.Lock()
+	defer b.Unlock()
+
+	b.closed = true
+	return b.channel.Close()
+}
+
+// open creates channel with exchange and subscribes registered handlers. Must be called with lock held
+func (b *broadcaster) open() error {
+	channel, err := b.client.Channel()
+	if err != nil {
+		return err
+	}
+	if err := channel.ExchangeDeclare(b.exchange, amqp.ExchangeFanout, true, false, false, false, nil); err != nil {
+		channel.Close()
+		return err
+	}
+	for _, handler := range b.handlers {
+		if err := b.consume(channel, handler); err != nil {
+			channel.Close()
+			return err
+		}
+	}
+	b.channel = channel
+	go b.watch(channel.NotifyClose(make(chan *amqp.Error, 1)))
+	return nil
+}
+func (b *broadcaster) consume(channel *amqp.Channel, handler func(message []byte)) error {
+	queue, err := channel.QueueDeclare("", false, true, true, false, nil)
+	if err != nil {
+		return err
+	}
+	if err := channel.QueueBind(queue.Name, "", b.exchange, false, nil); err != nil {
+		return err
+	}
+	deliveries, err := channel.Consume(queue.Name, "", true, true, false, false, nil)
+	if err != nil {
+		return err
+	}
+	go func() {
+		for d := range deliveries {
+			handler(d.Body)
+		}
+	}()
+	return nil
+}
+
+// watch reopens channel closed by broker. If connection itself is closed, the reason is kept for Err,
+// Publish and Subscribe, since there's nothing to reopen channel on
+func (b *broadcaster) watch(closed chan *amqp.Error) {
+	reason, ok := <-closed
+	if !ok {
+		// channel was closed by Close
+		return
+	}
+	delay := reopenDelay
+	for {
+		b.Lock()
+		if b.closed {
+			b.Unlock()
+			return
+		}
+		if b.client.IsClosed() {
+			b.err = fmt.Errorf("broadcaster stopped: connection closed: %w", reason)
+			b.Unlock()
+			return
+		}
+		err := b.open()
+		b.Unlock()
+		if err == nil {
+			return
+		}
+		var amqpErr *amqp.Error
+		if errors.As(err, &amqpErr) {
+			reason = amqpErr
+		}
+		time.Sleep(delay)
+		delay = min(2*delay, maxReopenDelay)
+	}
+}
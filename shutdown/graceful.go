@@ -5,14 +5,52 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 )
 
+var (
+	reservedMu sync.Mutex
+	reserved   = make(map[os.Signal]int)
+)
+
+// Reserve makes Graceful ignore signals while they're handled elsewhere, e.g. SIGHUP reopening log files.
+// Every Reserve must be paired with Release
+func Reserve(signals ...os.Signal) {
+	reservedMu.Lock()
+	defer reservedMu.Unlock()
+	for _, s := range signals {
+		reserved[s]++
+	}
+}
+
+// Release undoes Reserve, so Graceful shuts down on signals again once nobody else handles them
+func Release(signals ...os.Signal) {
+	reservedMu.Lock()
+	defer reservedMu.Unlock()
+	for _, s := range signals {
+		if reserved[s] <= 1 {
+			delete(reserved, s)
+		} else {
+			reserved[s]--
+		}
+	}
+}
+func isReserved(s os.Signal) bool {
+	reservedMu.Lock()
+	defer reservedMu.Unlock()
+	return reserved[s] > 0
+}
+
 func Graceful(closers ...io.Closer) {
 	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, syscall.SIGABRT, syscall.SIGQUIT, syscall.SIGHUP, os.Interrupt, syscall.SIGTERM)
+	// SIGHUP shuts service down like terminal hangup does by default, unless it's reserved, see logrus.RotatingFile
+	signal.Notify(sig, syscall.SIGABRT, syscall.SIGQUIT, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
 
 	signal := <-sig
+	for isReserved(signal) {
+		signal = <-sig
+	}
 
 	fmt.Printf("received signal %s, shutting down", signal.String())
 	for _, c := range closers {